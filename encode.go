@@ -0,0 +1,524 @@
+package former
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encode serializes src into form values using the same formfield tag rules
+// as Populate. Slices and arrays become repeated keys, maps become
+// "key:value" entries and nested structs use dot notation, so the result can
+// be fed back into Populate. Fields tagged with the omitempty option, as in
+// `formfield:"nick,omitempty"`, are left out when they hold a zero value.
+// Formatting tags are honored too: a `numfmt:"percent"` field holding 0.45
+// is written as "45%", a time.Time field tagged timeformat uses its first
+// format, a `durationformat:"iso8601"` field an ISO 8601 duration and a
+// `rune:"char"` field the character itself.
+func Encode(src any) (url.Values, error) {
+	return EncodeWithOptions(src, DefaultOptions())
+}
+
+// EncodeWithOptions is like Encode but honors opts. Nested keys are joined
+// with Options.NestingSeparator, as when decoding, and with
+// Options.EncodeNestedAsJSON set, nested structs are written as a single
+// JSON value instead.
+func EncodeWithOptions(src any, opts Options) (url.Values, error) {
+	rv := reflect.ValueOf(src)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("src must be a struct or a pointer to a struct")
+	}
+
+	e := &encoder{values: url.Values{}, opts: opts}
+	if err := e.encodeStruct(rv, ""); err != nil {
+		return nil, err
+	}
+	return e.values, nil
+}
+
+// encoder accumulates form values while walking a struct.
+type encoder struct {
+	values url.Values
+	opts   Options
+}
+
+func (e *encoder) encodeStruct(structValue reflect.Value, prefix string) error {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		formFieldName, opts := fieldTag(field)
+
+		if formFieldName == "" {
+			if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+				if err := e.encodeStruct(fieldValue, prefix); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if formFieldName == "-" || isRequestField(formFieldName) {
+			continue
+		}
+
+		if opts.Contains("omitempty") && isEmptyValue(fieldValue) {
+			continue
+		}
+
+		if opts.Contains("inline") && fieldValue.Kind() == reflect.Struct {
+			if err := e.encodeStruct(fieldValue, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fullFieldName := formFieldName
+		if prefix != "" {
+			fullFieldName = prefix + nestingSeparator(e.opts) + formFieldName
+		}
+
+		if isSetMap(field) {
+			if err := e.encodeSetMap(reflect.Indirect(fieldValue), fullFieldName); err != nil {
+				return fmt.Errorf("failed to encode field %s: %w", field.Name, err)
+			}
+			continue
+		}
+		if field.Tag.Get("mapmode") == "kvcsv" {
+			if err := e.encodeKVCSVMap(reflect.Indirect(fieldValue), fullFieldName); err != nil {
+				return fmt.Errorf("failed to encode field %s: %w", field.Name, err)
+			}
+			continue
+		}
+
+		if values, ok := formatTagged(field, fieldValue); ok {
+			for _, value := range values {
+				e.values.Add(fullFieldName, value)
+			}
+			continue
+		}
+
+		if err := e.encodeField(fieldValue, fullFieldName); err != nil {
+			return fmt.Errorf("failed to encode field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *encoder) encodeField(fieldValue reflect.Value, name string) error {
+	if isNullType(fieldValue.Type()) {
+		if !fieldValue.Field(1).Bool() {
+			return nil
+		}
+		return e.encodeField(fieldValue.Field(0), name)
+	}
+
+	if isValueType(fieldValue.Type()) {
+		if !fieldValue.IsZero() {
+			e.values.Add(name, formatValueType(fieldValue))
+		}
+		return nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Ptr:
+		if fieldValue.IsNil() {
+			return nil
+		}
+		return e.encodeField(fieldValue.Elem(), name)
+
+	case reflect.Struct:
+		if e.opts.EncodeNestedAsJSON {
+			return e.encodeJSON(fieldValue, name)
+		}
+		return e.encodeStruct(fieldValue, name)
+
+	case reflect.Slice, reflect.Array:
+		if fieldValue.Kind() == reflect.Array && fieldValue.Type().Elem().Kind() == reflect.Uint8 {
+			if raw := formatByteArray(fieldValue); isRawBytes([]string{raw}) {
+				e.values.Add(name, raw)
+				return nil
+			}
+		}
+		for i := 0; i < fieldValue.Len(); i++ {
+			s, err := formatValue(fieldValue.Index(i))
+			if err != nil {
+				return err
+			}
+			e.values.Add(name, s)
+		}
+		return nil
+
+	case reflect.Map:
+		return e.encodeMap(fieldValue, name)
+
+	default:
+		s, err := formatValue(fieldValue)
+		if err != nil {
+			return err
+		}
+		e.values.Add(name, s)
+		return nil
+	}
+}
+
+// encodeJSON adds a nested struct as one JSON value, which Populate decodes
+// the same way it does a JSON object posted for the field.
+func (e *encoder) encodeJSON(structValue reflect.Value, name string) error {
+	data, err := json.Marshal(structValue.Interface())
+	if err != nil {
+		return err
+	}
+	e.values.Add(name, string(data))
+	return nil
+}
+
+func (e *encoder) encodeMap(mapValue reflect.Value, name string) error {
+	entries, err := formatMapEntries(mapValue, ":")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		e.values.Add(name, entry)
+	}
+	return nil
+}
+
+// encodeKVCSVMap adds a map as a single "key=value,key=value" value, the
+// reverse of a `mapmode:"kvcsv"` field.
+func (e *encoder) encodeKVCSVMap(mapValue reflect.Value, name string) error {
+	if mapValue.Kind() != reflect.Map || mapValue.Len() == 0 {
+		return nil
+	}
+
+	entries, err := formatMapEntries(mapValue, "=")
+	if err != nil {
+		return err
+	}
+	e.values.Add(name, strings.Join(entries, ","))
+	return nil
+}
+
+// formatMapEntries renders each entry of a map as key, sep and value, sorted
+// by key. Slice values yield one entry per element.
+func formatMapEntries(mapValue reflect.Value, sep string) ([]string, error) {
+	type pair struct {
+		key   string
+		value reflect.Value
+	}
+
+	pairs := make([]pair, 0, mapValue.Len())
+	iter := mapValue.MapRange()
+	for iter.Next() {
+		key, err := formatValue(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair{key: key, value: iter.Value()})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+
+	var entries []string
+	for _, p := range pairs {
+		if p.value.Kind() == reflect.Slice {
+			for i := 0; i < p.value.Len(); i++ {
+				s, err := formatValue(p.value.Index(i))
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, p.key+sep+s)
+			}
+			continue
+		}
+
+		s, err := formatValue(p.value)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, p.key+sep+s)
+	}
+
+	return entries, nil
+}
+
+// encodeSetMap adds one value per key set to true, the reverse of a
+// `mapmode:"set"` field.
+func (e *encoder) encodeSetMap(mapValue reflect.Value, name string) error {
+	if mapValue.Kind() != reflect.Map {
+		return nil
+	}
+
+	var keys []string
+	iter := mapValue.MapRange()
+	for iter.Next() {
+		if !iter.Value().Bool() {
+			continue
+		}
+		key, err := formatValue(iter.Key())
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		e.values.Add(name, key)
+	}
+	return nil
+}
+
+// formatTagged renders fieldValue according to the formatting tags of
+// field, the reverse of what setStructField parses, so that tagged fields
+// read back as they were. It reports false when no tag applies, leaving the
+// value to encodeField.
+func formatTagged(field reflect.StructField, fieldValue reflect.Value) ([]string, bool) {
+	v := fieldValue
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	if field.Tag.Get("numfmt") == "percent" {
+		return formatPercents(v), true
+	}
+	if format, ok := field.Tag.Lookup("timeformat"); ok && v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return nil, true
+		}
+		return []string{formatTime(t, format)}, true
+	}
+	if field.Tag.Get("rune") == "char" && (v.Kind() == reflect.Int32 || v.Kind() == reflect.Uint8) {
+		if v.IsZero() {
+			return nil, true
+		}
+		if v.Kind() == reflect.Int32 {
+			return []string{string(rune(v.Int()))}, true
+		}
+		return []string{string([]byte{byte(v.Uint())})}, true
+	}
+	if field.Tag.Get("durationformat") == "iso8601" && v.Type() == durationType {
+		return []string{formatISODuration(time.Duration(v.Int()))}, true
+	}
+	return nil, false
+}
+
+// formatISODuration renders d as an ISO 8601 duration such as "PT1H30M",
+// the reverse of parseISODuration. Hours are not folded into days, and
+// fractions of a second are written to the nanosecond.
+func formatISODuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	n := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		n = -n
+	}
+	b.WriteString("PT")
+
+	hours, n := n/uint64(time.Hour), n%uint64(time.Hour)
+	minutes, n := n/uint64(time.Minute), n%uint64(time.Minute)
+	seconds, nanos := n/uint64(time.Second), n%uint64(time.Second)
+	if hours > 0 {
+		b.WriteString(strconv.FormatUint(hours, 10) + "H")
+	}
+	if minutes > 0 {
+		b.WriteString(strconv.FormatUint(minutes, 10) + "M")
+	}
+	if seconds > 0 || nanos > 0 {
+		b.WriteString(strconv.FormatUint(seconds, 10))
+		if nanos > 0 {
+			b.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", nanos), "0"))
+		}
+		b.WriteString("S")
+	}
+	return b.String()
+}
+
+// formatTime renders t according to a timeformat tag, using the first of
+// several formats separated by "|". It is the reverse of parseTime.
+func formatTime(t time.Time, format string) string {
+	format, _, _ = strings.Cut(format, "|")
+	switch format {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "":
+		return t.Format(time.RFC3339Nano)
+	default:
+		return t.Format(format)
+	}
+}
+
+// formatPercents renders the fractions in v, a float or a slice or array of
+// them, as percentages.
+func formatPercents(v reflect.Value) []string {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []string{formatPercent(v.Float(), v.Type().Bits())}
+	}
+
+	values := make([]string, v.Len())
+	for i := range values {
+		elem := v.Index(i)
+		for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Ptr {
+			values[i] = formatPercent(elem.Float(), elem.Type().Bits())
+		}
+	}
+	return values
+}
+
+// formatPercent renders the fraction f as a percentage, such as "45%" for
+// 0.45. The decimal point is moved in f's shortest representation instead
+// of multiplying by 100, which could add rounding noise such as
+// "56.99999999999999%" that wouldn't read back as f.
+func formatPercent(f float64, bits int) string {
+	if f == 0 {
+		return "0%"
+	}
+	mantissa, exp, ok := strings.Cut(strconv.FormatFloat(f, 'e', -1, bits), "e")
+	if !ok {
+		return mantissa + "%" // NaN or an infinity
+	}
+
+	sign, mantissa := "", strings.TrimPrefix(mantissa, "-")
+	if f < 0 {
+		sign = "-"
+	}
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	point := e + 3 // one digit before the point, moved two places right
+
+	switch {
+	case point <= 0:
+		digits = "0." + strings.Repeat("0", -point) + digits
+	case point >= len(digits):
+		digits += strings.Repeat("0", point-len(digits))
+	default:
+		digits = digits[:point] + "." + digits[point:]
+	}
+	return sign + digits + "%"
+}
+
+// formatByteArray renders a byte array as the raw string it was bound from,
+// dropping the zero bytes that padded a shorter value.
+func formatByteArray(v reflect.Value) string {
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return string(bytes.TrimRight(b, "\x00"))
+}
+
+// isEmptyValue reports whether v should be dropped by the omitempty option.
+// It follows the rules of encoding/json's omitempty, except that a struct
+// holding its zero value, such as a zero time.Time, is empty too.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		return v.IsZero()
+	}
+	return false
+}
+
+// formatValueType renders a type registered in valueParsers using its String
+// method, which may be declared on the pointer receiver. time.Time is written
+// as RFC 3339 so that it parses back, json.RawMessage as its raw bytes and
+// big.Float with as many digits as it takes to parse back exactly. Types
+// without a String method, such as an sql.Scanner, fall back to their
+// driver.Value.
+func formatValueType(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case json.RawMessage:
+		return string(value)
+	case big.Float:
+		return value.Text('g', -1)
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	switch value := ptr.Interface().(type) {
+	case fmt.Stringer:
+		return value.String()
+	case driver.Valuer:
+		dv, err := value.Value()
+		if err != nil || dv == nil {
+			return ""
+		}
+		if b, ok := dv.([]byte); ok {
+			return string(b)
+		}
+		return fmt.Sprint(dv)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// formatValue renders a single scalar value the way setFieldValue parses it.
+func formatValue(v reflect.Value) (string, error) {
+	if _, ok := valueParsers[v.Type()]; ok {
+		return formatValueType(v), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatValue(v.Elem())
+	default:
+		return "", fmt.Errorf("unsupported field type: %s", v.Kind())
+	}
+}
@@ -0,0 +1,489 @@
+package former
+
+import (
+	"net"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	ptr := "pointer"
+
+	src := struct {
+		Name    string            `formfield:"name"`
+		Age     int               `formfield:"age"`
+		Active  bool              `formfield:"active"`
+		Tags    []string          `formfield:"tags"`
+		Scores  [2]int            `formfield:"scores"`
+		Meta    map[string]string `formfield:"meta"`
+		Ptr     *string           `formfield:"ptr"`
+		NilPtr  *string           `formfield:"nilptr"`
+		Skipped string            `formfield:"-"`
+		NoTag   string
+		Contact Contact `formfield:"contact"`
+		Address
+		hidden string `formfield:"hidden"`
+	}{
+		Name:    "Jane",
+		Age:     30,
+		Active:  false,
+		Tags:    []string{"go", "web"},
+		Scores:  [2]int{1, 2},
+		Meta:    map[string]string{"b": "2", "a": "1"},
+		Ptr:     &ptr,
+		Skipped: "skipped",
+		NoTag:   "notag",
+		Contact: Contact{Phone: "555", Email: "jane@example.com"},
+		Address: Address{City: "NYC"},
+		hidden:  "hidden",
+	}
+
+	values, err := Encode(&src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := url.Values{
+		"name":          {"Jane"},
+		"age":           {"30"},
+		"active":        {"false"},
+		"tags":          {"go", "web"},
+		"scores":        {"1", "2"},
+		"meta":          {"a:1", "b:2"},
+		"ptr":           {"pointer"},
+		"contact.phone": {"555"},
+		"contact.email": {"jane@example.com"},
+		"street":        {""},
+		"city":          {"NYC"},
+		"zip":           {""},
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got %v, want %v", values, expected)
+	}
+}
+
+func TestEncode_ErrorCases(t *testing.T) {
+	t.Run("non-struct source", func(t *testing.T) {
+		if _, err := Encode("string"); err == nil {
+			t.Error("expected error for non-struct source")
+		}
+	})
+
+	t.Run("unsupported field type", func(t *testing.T) {
+		src := struct {
+			Fn func() `formfield:"fn"`
+		}{Fn: func() {}}
+
+		_, err := Encode(src)
+		if err == nil || !strings.Contains(err.Error(), "failed to encode field Fn") {
+			t.Errorf("error = %v, should mention the field", err)
+		}
+	})
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	type Form struct {
+		Name     string              `formfield:"name"`
+		Count    int64               `formfield:"count"`
+		Ratio    float64             `formfield:"ratio"`
+		Enabled  bool                `formfield:"enabled"`
+		Tags     []string            `formfield:"tags"`
+		Scores   [3]int              `formfield:"scores"`
+		Settings map[string]int      `formfield:"settings"`
+		Filters  map[string][]string `formfield:"filters"`
+		Nick     *string             `formfield:"nick"`
+		Person   Person              `formfield:"person"`
+		Rate     float64             `formfield:"rate" numfmt:"percent"`
+		Weights  []float64           `formfield:"weights" numfmt:"percent"`
+	}
+
+	nick := "gopher"
+	original := Form{
+		Name:     "round trip",
+		Count:    -12,
+		Ratio:    0.125,
+		Enabled:  true,
+		Tags:     []string{"a", "b"},
+		Scores:   [3]int{3, 2, 1},
+		Settings: map[string]int{"x": 1, "y": 2},
+		Filters:  map[string][]string{"color": {"red", "blue"}},
+		Nick:     &nick,
+		Person: Person{
+			Name:    "John",
+			Age:     40,
+			Address: Address{Street: "Main", City: "LA", ZipCode: "90001"},
+			Contact: Contact{Phone: "123", Email: "john@example.com"},
+		},
+		Rate:    0.45,
+		Weights: []float64{0.57, 0.001, -1.25},
+	}
+
+	values, err := Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values.Get("rate") != "45%" || strings.Join(values["weights"], ",") != "57%,0.1%,-125%" {
+		t.Errorf("got rate %v and weights %v, want percentages", values["rate"], values["weights"])
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Form
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(result, original) {
+		t.Errorf("got %+v, want %+v", result, original)
+	}
+}
+
+func TestEncode_ValueTypes(t *testing.T) {
+	type Endpoint struct {
+		URL     url.URL    `formfield:"url"`
+		IP      net.IP     `formfield:"ip"`
+		Network net.IPNet  `formfield:"network"`
+		Addr    netip.Addr `formfield:"addr"`
+		Empty   netip.Addr `formfield:"empty"`
+		Created time.Time  `formfield:"created"`
+	}
+
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	src := Endpoint{
+		URL:     url.URL{Scheme: "https", Host: "example.com", Path: "/a"},
+		IP:      net.ParseIP("127.0.0.1"),
+		Network: *network,
+		Addr:    netip.MustParseAddr("::1"),
+		Created: time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+	}
+
+	values, err := Encode(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := url.Values{
+		"url":     {"https://example.com/a"},
+		"ip":      {"127.0.0.1"},
+		"network": {"10.0.0.0/8"},
+		"addr":    {"::1"},
+		"created": {"2024-03-15T09:30:00Z"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got %v, want %v", values, expected)
+	}
+}
+
+func TestEncode_OmitEmpty(t *testing.T) {
+	type Form struct {
+		Nickname string            `formfield:"nickname,omitempty"`
+		Age      int               `formfield:"age,omitempty"`
+		Active   bool              `formfield:"active,omitempty"`
+		Tags     []string          `formfield:"tags,omitempty"`
+		Meta     map[string]string `formfield:"meta,omitempty"`
+		Ptr      *int              `formfield:"ptr,omitempty"`
+		Contact  Contact           `formfield:"contact,omitempty"`
+		Name     string            `formfield:"name"`
+		Count    int               `formfield:"count"`
+		Enabled  bool              `formfield:"enabled"`
+	}
+
+	t.Run("zero values", func(t *testing.T) {
+		values, err := Encode(Form{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := url.Values{
+			"name":    {""},
+			"count":   {"0"},
+			"enabled": {"false"},
+		}
+		if !reflect.DeepEqual(values, expected) {
+			t.Errorf("got %v, want %v", values, expected)
+		}
+	})
+
+	t.Run("non-zero values", func(t *testing.T) {
+		zero := 0
+		values, err := Encode(Form{
+			Nickname: "gopher",
+			Age:      7,
+			Active:   true,
+			Tags:     []string{"a"},
+			Ptr:      &zero,
+			Contact:  Contact{Phone: "555"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := url.Values{
+			"nickname":      {"gopher"},
+			"age":           {"7"},
+			"active":        {"true"},
+			"tags":          {"a"},
+			"ptr":           {"0"},
+			"contact.phone": {"555"},
+			"contact.email": {""},
+			"name":          {""},
+			"count":         {"0"},
+			"enabled":       {"false"},
+		}
+		if !reflect.DeepEqual(values, expected) {
+			t.Errorf("got %v, want %v", values, expected)
+		}
+	})
+
+	t.Run("populate ignores tag options", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("nickname=gopher&age=7"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Nickname != "gopher" || result.Age != 7 {
+			t.Errorf("got %+v", result)
+		}
+	})
+}
+
+func TestEncodeWithOptions_NestedAsJSON(t *testing.T) {
+	type Form struct {
+		Name    string   `formfield:"name"`
+		Person  Person   `formfield:"person"`
+		Backup  *Contact `formfield:"backup"`
+		Missing *Contact `formfield:"missing"`
+	}
+
+	original := Form{
+		Name: "order",
+		Person: Person{
+			Name:    "John",
+			Age:     40,
+			Address: Address{Street: "Main", City: "LA", ZipCode: "90001"},
+			Contact: Contact{Phone: "123", Email: "john@example.com"},
+		},
+		Backup: &Contact{Phone: "456"},
+	}
+
+	tests := []struct {
+		name   string
+		asJSON bool
+		check  func(t *testing.T, values url.Values)
+	}{
+		{
+			name:   "dot notation by default",
+			asJSON: false,
+			check: func(t *testing.T, values url.Values) {
+				if values.Get("person.contact.phone") != "123" || values.Get("backup.phone") != "456" {
+					t.Errorf("got %v, want dot-notation keys", values)
+				}
+				if values.Has("person") {
+					t.Errorf("got a JSON value for person: %v", values)
+				}
+			},
+		},
+		{
+			name:   "JSON",
+			asJSON: true,
+			check: func(t *testing.T, values url.Values) {
+				if want := `{"Phone":"456","Email":""}`; values.Get("backup") != want {
+					t.Errorf("backup = %s, want %s", values.Get("backup"), want)
+				}
+				if values.Has("person.name") || values.Has("missing") {
+					t.Errorf("got unexpected keys: %v", values)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.EncodeNestedAsJSON = tt.asJSON
+
+			values, err := EncodeWithOptions(original, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, values)
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, original) {
+				t.Errorf("round trip: got %+v, want %+v", result, original)
+			}
+		})
+	}
+}
+
+func TestEncodeWithOptions_NestingSeparator(t *testing.T) {
+	type Form struct {
+		Name    string  `formfield:"name"`
+		Person  Person  `formfield:"person"`
+		Contact Contact `formfield:"contact"`
+	}
+
+	original := Form{
+		Name: "order",
+		Person: Person{
+			Name:    "John",
+			Address: Address{City: "LA"},
+		},
+		Contact: Contact{Email: "john@example.com"},
+	}
+
+	opts := DefaultOptions()
+	opts.NestingSeparator = "__"
+
+	values, err := EncodeWithOptions(original, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values.Get("contact__email") != "john@example.com" || values.Get("person__city") != "LA" {
+		t.Errorf("got %v, want keys joined with __", values)
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Form
+	if err := PopulateWithOptions(req, &result, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, original) {
+		t.Errorf("round trip: got %+v, want %+v", result, original)
+	}
+}
+
+func TestEncode_TimeFormat(t *testing.T) {
+	type Form struct {
+		Birthday time.Time  `formfield:"birthday" timeformat:"2006-01-02"`
+		Seen     time.Time  `formfield:"seen" timeformat:"unix"`
+		Clicked  time.Time  `formfield:"clicked" timeformat:"unixmilli"`
+		Expires  *time.Time `formfield:"expires" timeformat:"unix"`
+		Due      time.Time  `formfield:"due" timeformat:"02/01/2006|2006-01-02"`
+		Never    time.Time  `formfield:"never" timeformat:"unix"`
+	}
+
+	expires := time.Unix(1710500000, 0)
+	original := Form{
+		Birthday: time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC),
+		Seen:     time.Unix(1710495000, 0),
+		Clicked:  time.UnixMilli(1710495000123),
+		Expires:  &expires,
+		Due:      time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	values, err := Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"birthday": {"1990-05-01"},
+		"seen":     {"1710495000"},
+		"clicked":  {"1710495000123"},
+		"expires":  {"1710500000"},
+		"due":      {"15/03/2024"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got %v, want %v", values, expected)
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Form
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Birthday.Equal(original.Birthday) || !result.Seen.Equal(original.Seen) ||
+		!result.Clicked.Equal(original.Clicked) || !result.Expires.Equal(expires) || !result.Due.Equal(original.Due) {
+		t.Errorf("round trip: got %+v, want %+v", result, original)
+	}
+}
+
+func TestEncode_ISODuration(t *testing.T) {
+	type Form struct {
+		Timeout time.Duration `formfield:"timeout" durationformat:"iso8601"`
+	}
+
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{0, "PT0S"},
+		{90 * time.Minute, "PT1H30M"},
+		{36*time.Hour + 5*time.Second, "PT36H5S"},
+		{1500 * time.Millisecond, "PT1.5S"},
+		{time.Second + 123456789*time.Nanosecond, "PT1.123456789S"},
+		{-45 * time.Second, "-PT45S"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			values, err := Encode(Form{Timeout: tt.duration})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := values.Get("timeout"); got != tt.want {
+				t.Errorf("encoded %v as %q, want %q", tt.duration, got, tt.want)
+			}
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			var result Form
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Timeout != tt.duration {
+				t.Errorf("round trip: got %v, want %v", result.Timeout, tt.duration)
+			}
+		})
+	}
+}
+
+func TestEncode_RuneChar(t *testing.T) {
+	type Form struct {
+		Initial rune  `formfield:"initial" rune:"char"`
+		Grade   byte  `formfield:"grade" rune:"char"`
+		Symbol  *rune `formfield:"symbol" rune:"char"`
+		Unset   rune  `formfield:"unset" rune:"char"`
+	}
+
+	symbol := '€'
+	original := Form{Initial: 'A', Grade: 'b', Symbol: &symbol}
+
+	values, err := Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{"initial": {"A"}, "grade": {"b"}, "symbol": {"€"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got %v, want %v", values, expected)
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var result Form
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, original) {
+		t.Errorf("round trip: got %+v, want %+v", result, original)
+	}
+}
@@ -0,0 +1,107 @@
+package former
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BindError reports a form value that could not be converted into its
+// target struct field. Use errors.As to recover it from the error returned
+// by Populate and map failures back to individual form inputs.
+type BindError struct {
+	// Field is the Go name of the struct field being set.
+	Field string
+	// Key is the form key the values were read from.
+	Key string
+	// Kind is the kind of the struct field.
+	Kind reflect.Kind
+	// Err is the underlying conversion error.
+	Err error
+	// Message is the field's msg tag, if it has one. When set it replaces
+	// the generated text of Error, so it can be shown to users as is.
+	Message string
+}
+
+func (e *BindError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if idxErr, ok := e.Err.(*indexError); ok {
+		return fmt.Sprintf("failed to set field %s: %s[%d]: %v", e.Field, e.Key, idxErr.index, idxErr.err)
+	}
+	return fmt.Sprintf("failed to set field %s: %v", e.Field, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+func newBindError(field reflect.StructField, key string, err error) *BindError {
+	return &BindError{
+		Field:   field.Name,
+		Key:     key,
+		Kind:    field.Type.Kind(),
+		Err:     err,
+		Message: field.Tag.Get("msg"),
+	}
+}
+
+// Errors is returned by BindAndValidate when one or more fields failed. It
+// holds a *BindError per failing field, in the order the fields were bound,
+// so a handler can report every problem with a form at once.
+type Errors []*BindError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual errors, so errors.As finds the first
+// *BindError and errors.Is looks through each of them.
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// indexError records which slice or array element failed to convert.
+type indexError struct {
+	index int
+	err   error
+}
+
+func (e *indexError) Error() string {
+	return fmt.Sprintf("index %d: %v", e.index, e.err)
+}
+
+func (e *indexError) Unwrap() error {
+	return e.err
+}
+
+// StructTagError reports a struct tag that cannot be used, such as a min
+// bound that is not a number or a pattern that does not compile. Populate
+// checks every tag of a struct type before binding into it, so a malformed
+// tag fails on the first request rather than only when a matching value
+// arrives.
+type StructTagError struct {
+	// Field is the Go name of the field carrying the tag.
+	Field string
+	// Tag is the tag key, such as "min" or "formfield".
+	Tag string
+	// Err describes what is wrong with the tag.
+	Err error
+}
+
+func (e *StructTagError) Error() string {
+	return fmt.Sprintf("invalid %s tag on field %s: %v", e.Tag, e.Field, e.Err)
+}
+
+func (e *StructTagError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,356 @@
+package former
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBindError(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		target    any
+		wantField string
+		wantKey   string
+		wantKind  reflect.Kind
+	}{
+		{
+			name: "top-level int",
+			body: "age=abc",
+			target: &struct {
+				Age int `formfield:"age"`
+			}{},
+			wantField: "Age",
+			wantKey:   "age",
+			wantKind:  reflect.Int,
+		},
+		{
+			name: "nested field",
+			body: "contact.zip=abc",
+			target: &struct {
+				Contact struct {
+					Zip uint `formfield:"zip"`
+				} `formfield:"contact"`
+			}{},
+			wantField: "Zip",
+			wantKey:   "contact.zip",
+			wantKind:  reflect.Uint,
+		},
+		{
+			name: "pointer field",
+			body: "ratio=abc",
+			target: &struct {
+				Ratio *float64 `formfield:"ratio"`
+			}{},
+			wantField: "Ratio",
+			wantKey:   "ratio",
+			wantKind:  reflect.Ptr,
+		},
+		{
+			name: "map field",
+			body: "counts[a]=abc",
+			target: &struct {
+				Counts map[string]int `formfield:"counts"`
+			}{},
+			wantField: "Counts",
+			wantKey:   "counts",
+			wantKind:  reflect.Map,
+		},
+		{
+			name: "invalid JSON for a nested struct",
+			body: "profile={bad}",
+			target: &struct {
+				Profile struct {
+					Bio string `json:"bio"`
+				} `formfield:"profile"`
+			}{},
+			wantField: "Profile",
+			wantKey:   "profile",
+			wantKind:  reflect.Struct,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			err := Populate(req, tt.target)
+
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) {
+				t.Fatalf("expected *BindError, got %v", err)
+			}
+			if bindErr.Field != tt.wantField {
+				t.Errorf("Field: got %q, want %q", bindErr.Field, tt.wantField)
+			}
+			if bindErr.Key != tt.wantKey {
+				t.Errorf("Key: got %q, want %q", bindErr.Key, tt.wantKey)
+			}
+			if bindErr.Kind != tt.wantKind {
+				t.Errorf("Kind: got %v, want %v", bindErr.Kind, tt.wantKind)
+			}
+			if !strings.Contains(err.Error(), "failed to set field "+tt.wantField) {
+				t.Errorf("error = %v, should mention the field", err)
+			}
+		})
+	}
+
+	t.Run("unwraps to the conversion error", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("age=abc"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result struct {
+			Age int `formfield:"age"`
+		}
+		err := Populate(req, &result)
+		if !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("expected error to wrap strconv.ErrSyntax, got %v", err)
+		}
+	})
+}
+
+func TestBindError_ElementIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		target any
+		want   string
+	}{
+		{
+			name: "slice element",
+			body: "tags=1&tags=2&tags=x",
+			target: &struct {
+				Tags []int `formfield:"tags"`
+			}{},
+			want: "failed to set field Tags: tags[2]: ",
+		},
+		{
+			name: "array element",
+			body: "scores=1&scores=oops",
+			target: &struct {
+				Scores [3]uint `formfield:"scores"`
+			}{},
+			want: "failed to set field Scores: scores[1]: ",
+		},
+		{
+			name: "pointer to slice element",
+			body: "ratios=0.5&ratios=half",
+			target: &struct {
+				Ratios *[]float64 `formfield:"ratios"`
+			}{},
+			want: "failed to set field Ratios: ratios[1]: ",
+		},
+		{
+			name: "element inside a struct array",
+			body: "stops[1].scores=1&stops[1].scores=x",
+			target: &struct {
+				Stops [2]struct {
+					Scores []int `formfield:"scores"`
+				} `formfield:"stops"`
+			}{},
+			want: "failed to set field Stops: failed to set field Scores: stops[1].scores[1]: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			err := Populate(req, tt.target)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("error = %v, should start with %q", err, tt.want)
+			}
+			if !errors.Is(err, strconv.ErrSyntax) {
+				t.Errorf("expected error to wrap strconv.ErrSyntax, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStructTagError(t *testing.T) {
+	type Nested struct {
+		Code string `formfield:"code" minlen:"two"`
+	}
+
+	tests := []struct {
+		name      string
+		target    any
+		wantField string
+		wantTag   string
+		want      string
+	}{
+		{
+			name: "malformed pattern",
+			target: &struct {
+				Slug string `formfield:"slug" pattern:"^[a-z+$"`
+			}{},
+			wantField: "Slug",
+			wantTag:   "pattern",
+			want:      `invalid pattern tag on field Slug: invalid pattern "^[a-z+$": `,
+		},
+		{
+			name: "non-numeric min",
+			target: &struct {
+				Age int `formfield:"age" min:"ten"`
+			}{},
+			wantField: "Age",
+			wantTag:   "min",
+			want:      `invalid min tag on field Age: invalid min bound "ten": `,
+		},
+		{
+			name: "non-numeric max on slice elements",
+			target: &struct {
+				Scores []uint `formfield:"scores" max:"-1"`
+			}{},
+			wantField: "Scores",
+			wantTag:   "max",
+			want:      `invalid max tag on field Scores: invalid max bound "-1": `,
+		},
+		{
+			name: "min on a string",
+			target: &struct {
+				Name string `formfield:"name" min:"1"`
+			}{},
+			wantField: "Name",
+			wantTag:   "min",
+			want:      "invalid min tag on field Name: min is not supported for string fields",
+		},
+		{
+			name: "non-numeric oneof option",
+			target: &struct {
+				Level *int `formfield:"level" oneof:"1 two"`
+			}{},
+			wantField: "Level",
+			wantTag:   "oneof",
+			want:      `invalid oneof tag on field Level: invalid oneof option "two": `,
+		},
+		{
+			name: "maxlen on an int",
+			target: &struct {
+				Count int `formfield:"count" maxlen:"3"`
+			}{},
+			wantField: "Count",
+			wantTag:   "maxlen",
+			want:      "invalid maxlen tag on field Count: maxlen is not supported for int fields",
+		},
+		{
+			name: "unknown formfield option",
+			target: &struct {
+				Name string `formfield:"name,omitempty,inlined"`
+			}{},
+			wantField: "Name",
+			wantTag:   "formfield",
+			want:      `invalid formfield tag on field Name: unknown option "inlined"`,
+		},
+		{
+			name: "rune tag on an int",
+			target: &struct {
+				Grade int `formfield:"grade" rune:"char"`
+			}{},
+			wantField: "Grade",
+			wantTag:   "rune",
+			want:      "invalid rune tag on field Grade: requires a rune or byte field, got int",
+		},
+		{
+			name: "nested struct",
+			target: &struct {
+				Items map[string]*Nested `formfield:"items"`
+			}{},
+			wantField: "Code",
+			wantTag:   "minlen",
+			want:      `invalid minlen tag on field Code: invalid minlen bound "two": `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No values are posted: malformed tags fail before any binding.
+			req := httptest.NewRequest("POST", "/", strings.NewReader(""))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			err := Populate(req, tt.target)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Fatalf("error = %v, should start with %q", err, tt.want)
+			}
+
+			var tagErr *StructTagError
+			if !errors.As(err, &tagErr) {
+				t.Fatalf("expected *StructTagError, got %T", err)
+			}
+			if tagErr.Field != tt.wantField || tagErr.Tag != tt.wantTag {
+				t.Errorf("got Field=%q Tag=%q, want Field=%q Tag=%q", tagErr.Field, tagErr.Tag, tt.wantField, tt.wantTag)
+			}
+		})
+	}
+
+	t.Run("well-formed tags", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=abc"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		type node struct {
+			Value int   `formfield:"value" min:"0"`
+			Next  *node `formfield:"next"`
+		}
+
+		var result struct {
+			Name  string  `formfield:"name,omitempty" minlen:"1" pattern:"^[a-z]+$" oneof:"abc def"`
+			Price float64 `formfield:"price" min:"0.5" max:"9.99"`
+			Grade *rune   `formfield:"grade" rune:"char"`
+			List  node    `formfield:"list"`
+		}
+		if err := Populate(req, &result); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestBindError_Message(t *testing.T) {
+	type Form struct {
+		Email string `formfield:"email" pattern:"^[^@]+@[^@]+$" msg:"Please enter a valid email address"`
+		Age   int    `formfield:"age" min:"18" msg:"You must be an adult"`
+		Count *int   `formfield:"count" msg:"Count must be a whole number"`
+		Plain int    `formfield:"plain"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wrapped error
+	}{
+		{name: "validation failure", body: "email=nope", want: "Please enter a valid email address"},
+		{name: "bound violated", body: "age=12", want: "You must be an adult"},
+		{name: "conversion failure", body: "count=1.5", want: "Count must be a whole number", wrapped: strconv.ErrSyntax},
+		{name: "no msg tag", body: "plain=x", want: `failed to set field Plain: strconv.ParseInt: parsing "x": invalid syntax`, wrapped: strconv.ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) {
+				t.Fatalf("expected *BindError, got %T", err)
+			}
+			if bindErr.Err == nil {
+				t.Error("expected the underlying error to be kept")
+			}
+			if tt.wrapped != nil && !errors.Is(err, tt.wrapped) {
+				t.Errorf("expected error to wrap %v", tt.wrapped)
+			}
+		})
+	}
+}
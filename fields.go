@@ -0,0 +1,67 @@
+package former
+
+import "reflect"
+
+// FieldNames returns the form keys that Populate reads for v, which may be a
+// struct, a pointer to one or its reflect.Type. Nested structs contribute
+// their fields in dot notation, embedded and inline structs contribute theirs
+// directly, and skipped or untagged fields are left out. Maps and slices of
+// structs appear under their own key only, since their entries are named by
+// the client. It is meant for generating forms or client code from the
+// struct definition; the keys follow DefaultOptions.
+func FieldNames(v any) []string {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		return nil
+	}
+	t = derefType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	d := &decoder{opts: DefaultOptions()}
+	return d.fieldNames(t, "", nil, map[reflect.Type]bool{})
+}
+
+// fieldNames appends the form keys of struct type t under prefix to names.
+// active holds the struct types being walked, so recursive types stop at the
+// field that refers back to an enclosing type.
+func (d *decoder) fieldNames(t reflect.Type, prefix string, names []string, active map[reflect.Type]bool) []string {
+	active[t] = true
+	defer delete(active, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, opts := fieldTag(field)
+		if name == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && d.opts.FlattenEmbedded && !active[field.Type] {
+				names = d.fieldNames(field.Type, prefix, names, active)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "-" || isRequestField(name) {
+			continue
+		}
+
+		if opts.Contains("inline") && field.Type.Kind() == reflect.Struct && !active[field.Type] {
+			names = d.fieldNames(field.Type, prefix, names, active)
+			continue
+		}
+
+		key := d.joinKey(prefix, name)
+		base := derefType(field.Type)
+		if base.Kind() == reflect.Struct && !isValueType(base) && !isFileType(field.Type) && !active[base] {
+			names = d.fieldNames(base, key, names, active)
+			continue
+		}
+		names = append(names, key)
+	}
+	return names
+}
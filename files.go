@@ -0,0 +1,119 @@
+package former
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+// FileUpload describes an uploaded file without opening it. Bind a
+// FileUpload or []FileUpload field to read an upload's name, size and
+// content type straight from the struct; call Header.Open to read the data.
+type FileUpload struct {
+	// Header is the underlying multipart file header.
+	Header *multipart.FileHeader
+	// Filename is the name the client gave the file.
+	Filename string
+	// Size is the length of the file in bytes.
+	Size int64
+	// ContentType is the Content-Type of the file's part, if the client sent one.
+	ContentType string
+}
+
+// newFileUpload describes the upload behind header.
+func newFileUpload(header *multipart.FileHeader) FileUpload {
+	return FileUpload{
+		Header:      header,
+		Filename:    header.Filename,
+		Size:        header.Size,
+		ContentType: header.Header.Get("Content-Type"),
+	}
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.SliceOf(fileHeaderType)
+	fileUploadType      = reflect.TypeOf(FileUpload{})
+	fileUploadSliceType = reflect.SliceOf(fileUploadType)
+)
+
+// isFileType reports whether fields of type t bind from uploaded files
+// rather than form values.
+func isFileType(t reflect.Type) bool {
+	switch t {
+	case fileHeaderType, fileHeaderSliceType, fileUploadType, fileUploadSliceType:
+		return true
+	}
+	return false
+}
+
+// getFiles returns the uploaded file headers posted under fieldName.
+func (d *decoder) getFiles(fieldName string) []*multipart.FileHeader {
+	return lookupFiles(d.multipartForm, d.normalizeKey(fieldName))
+}
+
+// lookupFiles returns the headers posted under fieldName, followed by those
+// posted under fieldName+"[]", the name many frontends give multi-file
+// inputs.
+func lookupFiles(form *multipart.Form, fieldName string) []*multipart.FileHeader {
+	if form == nil {
+		return nil
+	}
+
+	plain, bracketed := form.File[fieldName], form.File[fieldName+"[]"]
+	if len(bracketed) == 0 {
+		return plain
+	}
+	return append(slices.Clip(plain), bracketed...)
+}
+
+// GetFiles returns the headers of every file uploaded under fieldName,
+// including those posted as fieldName+"[]". Like GetFile it expects the
+// multipart form to be parsed already, and it returns http.ErrMissingFile
+// when there are no uploads.
+func GetFiles(r *http.Request, fieldName string) ([]*multipart.FileHeader, error) {
+	if r.MultipartForm == nil {
+		return nil, fmt.Errorf("no multipart form data")
+	}
+
+	headers := lookupFiles(r.MultipartForm, fieldName)
+	if len(headers) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return headers, nil
+}
+
+// checkFileSizes returns an error naming the first of headers larger than
+// Options.MaxFileSize, if a limit is set.
+func (d *decoder) checkFileSizes(headers []*multipart.FileHeader) error {
+	if d.opts.MaxFileSize <= 0 {
+		return nil
+	}
+	for _, header := range headers {
+		if header.Size > d.opts.MaxFileSize {
+			return fmt.Errorf("file %q is %d bytes, over the limit of %d", header.Filename, header.Size, d.opts.MaxFileSize)
+		}
+	}
+	return nil
+}
+
+// setFiles stores headers in a field accepted by isFileType. Single file
+// fields receive the first upload.
+func setFiles(fieldValue reflect.Value, headers []*multipart.FileHeader) {
+	switch fieldValue.Type() {
+	case fileHeaderType:
+		fieldValue.Set(reflect.ValueOf(headers[0]))
+	case fileHeaderSliceType:
+		fieldValue.Set(reflect.ValueOf(headers))
+	case fileUploadType:
+		fieldValue.Set(reflect.ValueOf(newFileUpload(headers[0])))
+	case fileUploadSliceType:
+		uploads := make([]FileUpload, len(headers))
+		for i, header := range headers {
+			uploads[i] = newFileUpload(header)
+		}
+		fieldValue.Set(reflect.ValueOf(uploads))
+	}
+}
@@ -27,12 +27,34 @@
 //
 // Former supports all basic Go types and many complex types:
 //
-//   - Basic types: string, bool, int*, uint*, float32, float64
-//   - Slices: []string, []int, etc. (multiple form values with same name)
-//   - Arrays: [N]T (fills up to array capacity)
-//   - Maps: map[string]string (expects "key:value" format)
+//   - Basic types: string, bool, int*, uint*, float32, float64, complex64, complex128
+//   - Arbitrary precision numbers: big.Int, big.Float
+//   - Percentages: a float tagged `numfmt:"percent"` reads "45%" as 0.45
+//   - Slices: []string, []int, etc. (multiple form values with same name, or a
+//     single JSON array); slices of structs or struct pointers use name[i].field
+//   - Arrays: [N]T (fills up to array capacity); arrays of structs use name[i].field,
+//     and byte arrays take the raw bytes of a single non-numeric value,
+//     truncated or zero padded
+//   - Maps: map[K]V with any scalar key type (expects "key:value" format,
+//     name[key]=value, or a single JSON object); a bool map tagged
+//     `mapmode:"set"` takes each value as a key set to true, and
+//     `mapmode:"kvcsv"` reads "key=value,key=value" pairs
 //   - Pointers: *T (automatically initialized if values are present)
 //   - Structs: nested structs with their own formfield tags
+//   - Network types: url.URL, net.IP, net.IPNet, netip.Addr, netip.Prefix
+//   - Email addresses: mail.Address, such as "Jane <jane@example.com>"
+//   - time.Time: RFC 3339 by default; a `timeformat` tag sets a layout, or
+//     "unix" or "unixmilli" for numeric timestamps, and several formats
+//     separated by "|" are tried in order
+//   - time.Duration: nanoseconds, or ISO 8601 durations such as "PT1H30M"
+//     with a `durationformat:"iso8601"` tag
+//   - database/sql nullable types: sql.NullString, sql.NullInt64, sql.Null[T], etc.
+//   - Types implementing FormUnmarshaler, which receive every posted value
+//   - Types implementing sql.Scanner, which are scanned from the value as a string
+//   - Types implementing json.Unmarshaler (plain values are passed as JSON strings)
+//   - json.RawMessage, which keeps the value verbatim
+//   - Empty interfaces (any): a string, a []string for repeated keys, or the
+//     decoded value when the input is a JSON object or array
 //
 // # Nested Structures
 //
@@ -57,25 +79,76 @@
 //	}
 //	// Form data: shipping.street=Main St&shipping.city=NYC
 //
-// 3. Nested structs as JSON:
+// 3. Nested structs with the inline option - fields are treated as top-level,
+// just like embedding:
+//
+//	type Order struct {
+//		Shipping Address `formfield:",inline"`
+//	}
+//	// Form data: street=Main St&city=NYC
+//
+// 4. Nested structs as JSON:
 //
 //	type User struct {
 //		Profile Profile `formfield:"profile"`
 //	}
 //	// Form data: profile={"age":30,"bio":"Developer"}
 //
+// 5. Maps of structs - each map key gets its own struct, bound with bracket
+// and dot notation:
+//
+//	type Customer struct {
+//		Addresses map[string]Address `formfield:"addresses"`
+//	}
+//	// Form data: addresses[home].city=NYC&addresses[work].city=Boston
+//
 // # Special Features
 //
 // - Fields with tag `formfield:"-"` are skipped
 // - Checkbox values "on", "1", and "true" are treated as true for bool fields
-// - File uploads can be retrieved using GetFile function
+// - Fields tagged `rune:"char"` take a character instead of a number: a rune
+// gets its code point and a byte gets the first byte of the value
+// - File uploads can be retrieved using GetFile and GetFiles, or bound into
+// *multipart.FileHeader, []*multipart.FileHeader, FileUpload and []FileUpload
+// fields; "name[]" uploads count as "name"
+// - Encode turns a struct back into url.Values using the same tags
+// - FieldNames lists the form keys a struct binds from
+// - HasField and FieldValue check single keys of an already parsed form
+// - Tags "@method", "@path" and "@url" bind those parts of the request
+//
+// # Validation
+//
+// If the target implements Validator, its Validate method is called once all
+// fields are bound and any error it returns is passed back to the caller:
+//
+//	func (f *SignupForm) Validate() error {
+//		if f.Password != f.Confirm {
+//			return errors.New("passwords do not match")
+//		}
+//		return nil
+//	}
+//
+// Fields can also declare simple rules with tags:
+//
+//	Role string `formfield:"role" oneof:"admin editor viewer"`
+//	Qty  int    `formfield:"qty" min:"1" max:"100"`
+//	Name string `formfield:"name" minlen:"2" maxlen:"50"`
+//	Slug string `formfield:"slug" pattern:"^[a-z0-9-]+$"`
+//
+// A required:"true" tag rejects a field left at its zero value, and a
+// requiredif tag such as `requiredif:"type=business"` does so only when the
+// sibling with the form key "type" holds "business".
+//
+// Populate stops at the first failing field. BindAndValidate checks them all
+// and returns an Errors holding a *BindError for each.
 //
 // # Error Handling
 //
 // Former follows these error handling principles:
-// - Type conversion errors are returned immediately
+// - Type conversion errors are returned immediately as a *BindError
 // - Invalid JSON in struct fields returns an error
-// - The target must be a pointer to a struct
+// - The target must be a pointer to a struct, or to a map[string]any
+// - Malformed struct tags are reported as a *StructTagError before binding
 //
 // # Multipart Forms
 //
@@ -89,22 +162,316 @@
 package former
 
 import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"html"
+	"io"
+	"maps"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 func Populate(r *http.Request, dest any) error {
+	return PopulateWithOptions(r, dest, DefaultOptions())
+}
+
+// MustPopulate is like Populate but panics if binding fails. The panic value
+// is an error wrapping the one Populate returned. It is meant for tests and
+// quick prototypes; handlers serving real requests should use Populate and
+// reply to bad input with an error status.
+func MustPopulate(r *http.Request, dest any) {
+	if err := Populate(r, dest); err != nil {
+		panic(fmt.Errorf("former: MustPopulate: %w", err))
+	}
+}
+
+// PopulateGeneric allocates a T, binds the form into it as Populate does and
+// returns it, for handlers and generic wrappers that want the value without
+// declaring it first. T must be a struct type. On error the result is nil.
+func PopulateGeneric[T any](r *http.Request) (*T, error) {
+	dest := new(T)
+	if err := Populate(r, dest); err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// PopulateWithOptions is like Populate but binds according to opts. Start from
+// DefaultOptions and change only what you need, since some defaults are not
+// the zero value.
+func PopulateWithOptions(r *http.Request, dest any, opts Options) error {
+	return populateRequest(r, dest, opts, nil)
+}
+
+// BindAndValidate is like Populate but checks every field before returning,
+// instead of stopping at the first failure. Values that don't convert and
+// violations of the validation tags (required, requiredif, oneof, min, max,
+// minlen, maxlen and pattern) are all returned together as Errors. The
+// target's Validate method, if any, runs only when every field passed.
+func BindAndValidate(r *http.Request, dest any) error {
+	var errs Errors
+	return populateRequest(r, dest, DefaultOptions(), &errs)
+}
+
+// Validate reports the error Populate would return for r and dest without
+// changing dest: the form is bound into a copy of dest, including its
+// Validate method if it has one, and then discarded. Use it for pre-flight
+// checks before committing to an update.
+func Validate(r *http.Request, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("dest must be a pointer to a struct")
+	}
+
+	copied := reflect.New(rv.Elem().Type())
+	if rv.Elem().Kind() == reflect.Struct {
+		copied.Elem().Set(rv.Elem())
+		detachPointers(copied.Elem())
+	}
+	return Populate(r, copied.Interface())
+}
+
+// detachPointers points every settable pointer reachable from v at a copy
+// of its target, so that binding into v can't write through to the value v
+// was copied from. Slices and maps need no copy, since binding replaces
+// them instead of writing into them.
+func detachPointers(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		target := reflect.New(v.Type().Elem())
+		target.Elem().Set(v.Elem())
+		v.Set(target)
+		detachPointers(target.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				detachPointers(field)
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			detachPointers(v.Index(i))
+		}
+	}
+}
+
+// populateRequest implements PopulateWithOptions and, with a non-nil errs to
+// collect field failures in, BindAndValidate.
+func populateRequest(r *http.Request, dest any, opts Options, errs *Errors) error {
+	rv := reflect.ValueOf(dest)
+	isMap := rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Type() == anyMapType
+	if !isMap && (rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct) {
+		return fmt.Errorf("dest must be a pointer to a struct")
+	}
+
+	if isMap {
+		form, _, err := requestForm(r, anyMapType, opts)
+		if err != nil {
+			return err
+		}
+		if opts.NormalizeKeys != nil {
+			form = normalizeValues(form, opts.NormalizeKeys)
+		}
+		populateAnyMap(rv.Elem(), form)
+		return nil
+	}
+
+	d, err := newRequestDecoder(r, rv.Elem().Type(), opts)
+	if err != nil {
+		return err
+	}
+	d.errs = errs
+	return d.bind(rv.Elem())
+}
+
+// newRequestDecoder prepares r with requestForm and returns a decoder
+// binding it into a struct of type t.
+func newRequestDecoder(r *http.Request, t reflect.Type, opts Options) (*decoder, error) {
+	form, ordered, err := requestForm(r, t, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &decoder{form: form, multipartForm: r.MultipartForm, opts: opts, request: r, ordered: ordered}, nil
+}
+
+// requestForm parses r as opts ask and returns the form to bind, along with
+// its values in the order they were sent when t has fields tagged
+// formindex. Every entry point that reads
+// a request goes through it, so the request options set with
+// SetDefaultOptions hold for all of them.
+func requestForm(r *http.Request, t reflect.Type, opts Options) (url.Values, []formPair, error) {
+	restore, err := prepareBody(r, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer restore()
+
+	var ordered []formPair
+	if usesFormIndex(t, make(map[reflect.Type]bool)) {
+		if ordered, err = orderedFormValues(r, opts.PostFormOnly); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := parseRequest(r); err != nil {
+		return nil, nil, err
+	}
+
+	form := r.Form
+	if opts.PostFormOnly {
+		form = r.PostForm
+	}
+	if err := checkForm(form, opts); err != nil {
+		return nil, nil, err
+	}
+	return form, ordered, nil
+}
+
+// prepareBody applies the options that act on r's body before it is read:
+// Options.DecompressBody and the Content-Type check of Options.StrictParse.
+// A body that was already parsed is not decompressed again. The returned
+// function puts the original body back.
+func prepareBody(r *http.Request, opts Options) (func(), error) {
+	restore := func() {}
+	if opts.DecompressBody && r.Form == nil && r.MultipartForm == nil {
+		var err error
+		if restore, err = decompressBody(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.StrictParse {
+		if err := checkContentType(r); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+	return restore, nil
+}
+
+// checkForm rejects a parsed form that Options.StrictParse doesn't accept.
+func checkForm(form url.Values, opts Options) error {
+	if opts.StrictParse {
+		if _, ok := form[""]; ok {
+			return fmt.Errorf("malformed form: value without a key")
+		}
+	}
+	return nil
+}
+
+var anyMapType = reflect.TypeOf(map[string]any(nil))
+
+// populateAnyMap copies every key of form into a map[string]any, allocating
+// the map if it is nil. A key posted once is stored as a string and a
+// repeated key as a []string, the way the values would look in JSON.
+func populateAnyMap(mapValue reflect.Value, form url.Values) {
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMapWithSize(anyMapType, len(form)))
+	}
+	m := mapValue.Interface().(map[string]any)
+	for key, values := range form {
+		if len(values) == 1 {
+			m[key] = values[0]
+		} else {
+			m[key] = slices.Clone(values)
+		}
+	}
+}
+
+// Merge is like Populate with PATCH semantics: only fields that receive a
+// non-empty value are changed, and everything else in dest, including values
+// from an earlier Populate or Merge, is left as it was. Empty values are
+// ignored as with Options.PreserveOnEmpty, and maps gain or update the posted
+// keys instead of being replaced. Options.CheckboxSemantics is turned off,
+// since a bool that wasn't posted is left as it was too.
+func Merge(r *http.Request, dest any) error {
 	rv := reflect.ValueOf(dest)
 	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("dest must be a pointer to a struct")
 	}
 
+	opts := DefaultOptions()
+	opts.PreserveOnEmpty = true
+	opts.CheckboxSemantics = false
+
+	d, err := newRequestDecoder(r, rv.Elem().Type(), opts)
+	if err != nil {
+		return err
+	}
+	d.merge = true
+	return d.bind(rv.Elem())
+}
+
+// PopulateWithPrefix is like Populate but binds only the section of the form
+// under prefix, so a flat struct can take the "billing.*" keys of a larger
+// form: with the prefix "billing", a field tagged "city" reads "billing.city".
+func PopulateWithPrefix(r *http.Request, dest any, prefix string) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a struct")
+	}
+
+	d, err := newRequestDecoder(r, rv.Elem().Type(), DefaultOptions())
+	if err != nil {
+		return err
+	}
+	d.prefix = strings.TrimSuffix(prefix, d.separator())
+	return d.bind(rv.Elem())
+}
+
+// PopulateReport is like Populate but also returns a Report of which form
+// keys were read and which fields were left unset. The report covers the
+// fields visited before an error, if one is returned.
+func PopulateReport(r *http.Request, dest any) (Report, error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return Report{}, fmt.Errorf("dest must be a pointer to a struct")
+	}
+
+	d, err := newRequestDecoder(r, rv.Elem().Type(), DefaultOptions())
+	if err != nil {
+		return Report{}, err
+	}
+	d.report = &Report{}
+	err = d.bind(rv.Elem())
+	return d.finishReport(), err
+}
+
+// PopulateValue is like Populate but binds into rv, which must be a settable
+// struct value such as reflect.ValueOf(&s).Elem(). It lets frameworks that
+// already hold a reflect.Value bind without converting back to an interface.
+func PopulateValue(r *http.Request, rv reflect.Value) error {
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("value must be a struct, got %s", rv.Kind())
+	}
+	if !rv.CanSet() {
+		return fmt.Errorf("value must be settable")
+	}
+
+	d, err := newRequestDecoder(r, rv.Type(), DefaultOptions())
+	if err != nil {
+		return err
+	}
+	return d.bind(rv)
+}
+
+// parseRequest parses the request body according to its content type.
+func parseRequest(r *http.Request) error {
 	contentType := r.Header.Get("Content-Type")
 	if strings.HasPrefix(contentType, "multipart/form-data") {
 		if err := r.ParseMultipartForm(32 << 20); // 32MB max memory
@@ -116,143 +483,987 @@ func Populate(r *http.Request, dest any) error {
 			return fmt.Errorf("failed to parse form: %w", err)
 		}
 	}
+	return nil
+}
+
+// checkContentType returns an error when r carries a body that ParseForm
+// would skip because its Content-Type is missing or not a form encoding.
+func checkContentType(r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return fmt.Errorf("malformed form: unsupported Content-Type %q", contentType)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("malformed form: invalid Content-Type %q: %w", contentType, err)
+	}
+	if mediaType != "application/x-www-form-urlencoded" && mediaType != "multipart/form-data" {
+		return fmt.Errorf("malformed form: unsupported Content-Type %q", contentType)
+	}
+	return nil
+}
+
+// decompressBody swaps r.Body for a reader that undoes a gzip or deflate
+// Content-Encoding. The returned function closes that reader and puts the
+// original body back. Other encodings leave the body untouched.
+func decompressBody(r *http.Request) (func(), error) {
+	if r.Body == nil {
+		return func() {}, nil
+	}
+
+	var rc io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress body: %w", err)
+		}
+		rc = zr
+	case "deflate":
+		rc = flate.NewReader(r.Body)
+	default:
+		return func() {}, nil
+	}
+
+	body := r.Body
+	r.Body = rc
+	return func() {
+		rc.Close()
+		r.Body = body
+	}, nil
+}
+
+// PopulateMultipart binds an already parsed multipart form into dest, reading
+// values from form.Value and uploads from form.File. It is useful when
+// middleware has parsed the request already, or when no *http.Request is at
+// hand, as in tests.
+func PopulateMultipart(form *multipart.Form, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a struct")
+	}
+	if form == nil {
+		return fmt.Errorf("no multipart form data")
+	}
+	if usesFormIndex(rv.Elem().Type(), make(map[reflect.Type]bool)) {
+		return fmt.Errorf("formindex fields can't be bound from a multipart.Form, which doesn't keep the order values were sent in")
+	}
+
+	d := &decoder{form: url.Values(form.Value), multipartForm: form, opts: DefaultOptions()}
+	return d.bind(rv.Elem())
+}
+
+// PopulateContext is like Populate but stops when ctx is done. The standard
+// ParseForm and ParseMultipartForm do not watch the request context, so the
+// body is wrapped in a reader that checks ctx before every read; a cancelled
+// upload then aborts at the next chunk instead of being read to the end. The
+// returned error wraps ctx.Err() in that case.
+func PopulateContext(ctx context.Context, r *http.Request, dest any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if r.Body != nil {
+		body := r.Body
+		r.Body = &contextReader{ctx: ctx, body: body}
+		defer func() { r.Body = body }()
+	}
+
+	if err := Populate(r, dest); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// contextReader fails reads once its context is done.
+type contextReader struct {
+	ctx  context.Context
+	body io.ReadCloser
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.body.Read(p)
+}
+
+func (c *contextReader) Close() error {
+	return c.body.Close()
+}
+
+// decoder carries the parsed form and options through a single binding pass.
+type decoder struct {
+	form          url.Values
+	multipartForm *multipart.Form
+	opts          Options
+
+	// used records the form keys read during the pass, so that
+	// Options.DisallowUnknownFields can report the rest.
+	used map[string]bool
+
+	// field is the Go name of the field being bound, reported when
+	// Options.RecoverPanics turns a panic into an error.
+	field string
+
+	// merge makes maps gain entries instead of being replaced. See Merge.
+	merge bool
+
+	// prefix is the key of the section being bound, or empty for the whole
+	// form. See PopulateWithPrefix. The bare-key fallback is skipped when
+	// it is set, since bare keys lie outside the section.
+	prefix string
+
+	// report collects the outcome of each field for PopulateReport. It is
+	// nil for the other entry points.
+	report *Report
+
+	// request is the request being bound, used for cookies and the
+	// "@method"-style request fields. It is nil when binding a parsed
+	// multipart.Form on its own.
+	request *http.Request
+
+	// errs collects field failures for BindAndValidate instead of stopping
+	// at the first one. It is nil for the other entry points.
+	errs *Errors
+
+	// overJSON is set while dot-notation keys are applied over a struct
+	// decoded from JSON. The bare-key fallback is skipped then, so a
+	// top-level key can't overwrite a value the JSON supplied.
+	overJSON bool
+
+	// ordered holds the form's values in the order they were sent, with
+	// their keys, for fields tagged formindex. It is nil when the struct
+	// has none.
+	ordered []formPair
+}
+
+// bind populates structValue and then runs its Validate method, if any.
+func (d *decoder) bind(structValue reflect.Value) error {
+	if err := checkStructTags(structValue.Type()); err != nil {
+		return err
+	}
+
+	if d.opts.NormalizeKeys != nil {
+		d.normalizeForm()
+	}
+
+	if err := d.populate(structValue); err != nil {
+		return err
+	}
+	if d.errs != nil && len(*d.errs) > 0 {
+		return *d.errs
+	}
+
+	if d.opts.DisallowUnknownFields {
+		if err := d.checkUnknownFields(); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := structValue.Addr().Interface().(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
 
-	structValue := rv.Elem()
-	structType := structValue.Type()
+// populate binds the form into structValue. With Options.RecoverPanics set,
+// a panic along the way is returned as an error naming the field it hit.
+func (d *decoder) populate(structValue reflect.Value) (err error) {
+	if d.opts.RecoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic while setting field %s: %v", d.field, r)
+			}
+		}()
+	}
 
-	return populateStruct(structValue, structType, r, "")
+	return d.populateStruct(structValue, structValue.Type(), d.prefix)
 }
 
-func populateStruct(structValue reflect.Value, structType reflect.Type, r *http.Request, prefix string) error {
+func (d *decoder) populateStruct(structValue reflect.Value, structType reflect.Type, prefix string) error {
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
-		fieldValue := structValue.Field(i)
 
-		if !fieldValue.CanSet() {
-			continue
+		var err error
+		if setter := d.setterFor(structValue, field); setter.IsValid() {
+			err = d.populateSetter(field, structValue.Field(i), setter, prefix)
+		} else {
+			err = d.populateField(field, structValue.Field(i), prefix)
+		}
+		if err != nil && !d.collectError(err) {
+			return err
 		}
+	}
 
-		formFieldName := field.Tag.Get("formfield")
+	return d.checkRequired(structValue, prefix)
+}
 
-		if formFieldName == "" {
-			if field.Anonymous && fieldValue.Kind() == reflect.Struct {
-				if err := populateStruct(fieldValue, fieldValue.Type(), r, prefix); err != nil {
-					return err
-				}
+// collectError records err when collecting errors for BindAndValidate and
+// reports whether it did, in which case binding moves on to the next field.
+// Only a *BindError is collected; anything else still ends binding.
+func (d *decoder) collectError(err error) bool {
+	bindErr, ok := err.(*BindError)
+	if !ok || d.errs == nil {
+		return false
+	}
+	*d.errs = append(*d.errs, bindErr)
+	return true
+}
+
+// populateField binds the form values for one field of a struct whose own
+// key is prefix.
+func (d *decoder) populateField(field reflect.StructField, fieldValue reflect.Value, prefix string) error {
+	d.field = field.Name
+
+	if header, ok := field.Tag.Lookup("formheader"); ok {
+		if !fieldValue.CanSet() || d.skipField(field) {
+			return nil
+		}
+		return d.populateHeader(field, fieldValue, header)
+	}
+	if param, ok := field.Tag.Lookup("formpath"); ok {
+		if !fieldValue.CanSet() || d.skipField(field) {
+			return nil
+		}
+		return d.populatePath(field, fieldValue, param)
+	}
+	if index, ok := field.Tag.Lookup("formindex"); ok {
+		if !fieldValue.CanSet() || d.skipField(field) {
+			return nil
+		}
+		return d.populateIndex(field, fieldValue, index)
+	}
+
+	formFieldName, opts := fieldTag(field)
+
+	// Embedded structs are flattened before the CanSet check: when their
+	// type is unexported the struct itself can't be set, but its
+	// exported fields are promoted and can.
+	if formFieldName == "" {
+		if field.Anonymous && fieldValue.Kind() == reflect.Struct && d.opts.FlattenEmbedded {
+			if err := d.populateStruct(fieldValue, fieldValue.Type(), prefix); err != nil {
+				return err
 			}
-			continue
 		}
+		return nil
+	}
 
-		if formFieldName == "-" {
-			continue
+	if !fieldValue.CanSet() {
+		return nil
+	}
+
+	if formFieldName == "-" || d.skipField(field) {
+		return nil
+	}
+
+	if isRequestField(formFieldName) {
+		if err := d.setRequestField(fieldValue, formFieldName); err != nil {
+			return newBindError(field, formFieldName, err)
+		}
+		return nil
+	}
+
+	if opts.Contains("inline") && fieldValue.Kind() == reflect.Struct {
+		if err := d.populateStruct(fieldValue, fieldValue.Type(), prefix); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	fullFieldName := d.joinKey(prefix, formFieldName)
+
+	if isFileType(fieldValue.Type()) {
+		headers := d.getFiles(fullFieldName)
+		if err := d.checkFileSizes(headers); err != nil {
+			return newBindError(field, fullFieldName, err)
+		}
+		if len(headers) > 0 {
+			setFiles(fieldValue, headers)
+		}
+		d.record(fullFieldName, len(headers) > 0)
+		return nil
+	}
+
+	if fieldValue.Kind() == reflect.Struct && !isValueType(fieldValue.Type()) {
+		return d.populateNestedStruct(field, fieldValue, fullFieldName)
+	}
+
+	if fieldValue.Kind() == reflect.Ptr && isCollectionType(fieldValue.Type().Elem()) {
+		return d.populateCollectionPtr(field, fieldValue, prefix)
+	}
+
+	if fieldValue.Kind() == reflect.Ptr {
+		baseType := fieldValue.Type()
+		for baseType.Kind() == reflect.Ptr {
+			baseType = baseType.Elem()
 		}
 
-		fullFieldName := formFieldName
-		if prefix != "" {
-			fullFieldName = prefix + "." + formFieldName
+		key := fullFieldName
+		values := d.getFieldValues(field, key)
+		if len(values) == 0 {
+			if aliasKey, alias := d.getAliasValues(field, prefix); len(alias) > 0 {
+				key, values = aliasKey, alias
+			}
+		}
+		if len(values) == 0 {
+			if fallbackKey, fallback := d.getFieldNameValues(field, prefix); len(fallback) > 0 {
+				key, values = fallbackKey, fallback
+			}
+		}
+		if len(values) == 0 {
+			values = d.getCookieValues(key)
 		}
 
-		if fieldValue.Kind() == reflect.Struct {
-			if values := getFormValues(r, fullFieldName); len(values) > 0 {
-				jsonLike := looksLikeJSON(values[0])
-				if jsonLike {
-					if err := json.Unmarshal([]byte(values[0]), fieldValue.Addr().Interface()); err != nil {
-						return fmt.Errorf("failed to parse JSON for field %s: %w", field.Name, err)
+		if d.isNullSentinel(values) {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			d.record(fullFieldName, true)
+			return nil
+		}
+		if d.opts.PreserveOnEmpty && allEmpty(values) {
+			values = nil
+		}
+		if len(values) > 0 && baseType.Kind() == reflect.Struct && !isValueType(baseType) && isBlankJSON(d.pick(values)) {
+			values = nil
+		}
+
+		hasValues := len(values) > 0
+		if !hasValues && baseType.Kind() == reflect.Struct && !isValueType(baseType) {
+			for j := 0; j < baseType.NumField(); j++ {
+				nestedField := baseType.Field(j)
+				nestedTag, _ := fieldTag(nestedField)
+				if nestedTag != "" && nestedTag != "-" {
+					nestedName := d.joinKey(fullFieldName, nestedTag)
+					if values := d.getFormValues(nestedName); len(values) > 0 {
+						hasValues = true
+						break
 					}
-					continue
 				}
 			}
+		}
 
-			if err := populateStruct(fieldValue, fieldValue.Type(), r, fullFieldName); err != nil {
+		if !hasValues {
+			d.record(fullFieldName, false)
+			return nil
+		}
+
+		target := indirect(fieldValue)
+
+		if target.Kind() == reflect.Struct && !isValueType(baseType) {
+			if err := d.populateNestedStruct(field, target, fullFieldName); err != nil {
 				return err
 			}
-			continue
+		} else if len(values) > 0 {
+			values, err := d.runFieldHook(field, values)
+			if err != nil {
+				return newBindError(field, key, err)
+			}
+			if err := d.setStructField(field, target, values); err != nil {
+				return newBindError(field, key, err)
+			}
+			if err := validateField(field, target); err != nil {
+				return newBindError(field, key, err)
+			}
+			d.record(fullFieldName, true)
 		}
+		return nil
+	}
 
-		if fieldValue.Kind() == reflect.Ptr {
-			hasValues := false
-
-			if values := getFormValues(r, fullFieldName); len(values) > 0 {
-				hasValues = true
-			} else if fieldValue.Type().Elem().Kind() == reflect.Struct {
-				elemType := fieldValue.Type().Elem()
-				for j := 0; j < elemType.NumField(); j++ {
-					nestedField := elemType.Field(j)
-					nestedTag := nestedField.Tag.Get("formfield")
-					if nestedTag != "" && nestedTag != "-" {
-						nestedName := fullFieldName + "." + nestedTag
-						if values := getFormValues(r, nestedName); len(values) > 0 {
-							hasValues = true
-							break
-						}
-					}
-				}
+	key := fullFieldName
+	values := d.getFieldValues(field, key)
+	if len(values) == 0 {
+		if aliasKey, alias := d.getAliasValues(field, prefix); len(alias) > 0 {
+			key, values = aliasKey, alias
+		}
+	}
+	if len(values) == 0 && prefix != "" && !d.overJSON && d.prefix == "" {
+		if fallback := d.getFormValues(formFieldName); len(fallback) > 0 {
+			key, values = formFieldName, fallback
+		}
+	}
+	if len(values) == 0 {
+		if fallbackKey, fallback := d.getFieldNameValues(field, prefix); len(fallback) > 0 {
+			key, values = fallbackKey, fallback
+		}
+	}
+	if len(values) == 0 {
+		values = d.getCookieValues(key)
+	}
+	if d.opts.PreserveOnEmpty && allEmpty(values) {
+		values = nil
+	}
+
+	values, err := d.runFieldHook(field, values)
+	if err != nil {
+		return newBindError(field, key, err)
+	}
+
+	if fieldValue.Kind() == reflect.Array && isStructElem(fieldValue.Type().Elem()) {
+		if indexKeys := d.getBracketStructKeys(fullFieldName); len(indexKeys) > 0 {
+			if err := d.setStructArray(fieldValue, fullFieldName, indexKeys); err != nil {
+				return newBindError(field, key, err)
 			}
+			return nil
+		}
+	}
 
-			if hasValues {
-				if fieldValue.IsNil() {
-					fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
-				}
+	if fieldValue.Kind() == reflect.Slice && isStructElem(fieldValue.Type().Elem()) {
+		if indexKeys := d.getBracketStructKeys(fullFieldName); len(indexKeys) > 0 {
+			if err := d.setStructSlice(fieldValue, fullFieldName, indexKeys); err != nil {
+				return newBindError(field, key, err)
+			}
+			return nil
+		}
+	}
 
-				if fieldValue.Elem().Kind() == reflect.Struct {
-					if err := populateStruct(fieldValue.Elem(), fieldValue.Elem().Type(), r, fullFieldName); err != nil {
-						return err
-					}
-				} else {
-					if values := getFormValues(r, fullFieldName); len(values) > 0 {
-						if err := setFieldValue(fieldValue.Elem(), values); err != nil {
-							return fmt.Errorf("failed to set field %s: %w", field.Name, err)
-						}
-					}
+	if fieldValue.Kind() == reflect.Map && !isValueType(fieldValue.Type()) && !implementsJSONUnmarshaler(fieldValue.Type()) && !isSetMap(field) {
+		if isStructElem(fieldValue.Type().Elem()) {
+			if mapKeys := d.getBracketStructKeys(fullFieldName); len(mapKeys) > 0 {
+				if err := d.setStructMap(fieldValue, fullFieldName, mapKeys); err != nil {
+					return newBindError(field, key, err)
 				}
+				return nil
 			}
-			continue
 		}
 
-		values := getFormValues(r, fullFieldName)
-		if len(values) == 0 {
-			if prefix != "" {
-				values = getFormValues(r, formFieldName)
+		if len(values) == 1 && looksLikeJSON(values[0]) {
+			if err := d.setMapJSON(fieldValue, values[0]); err != nil {
+				return newBindError(field, key, err)
 			}
-			if len(values) == 0 {
-				continue
+			d.record(fullFieldName, true)
+			return nil
+		}
+
+		var entries []mapEntry
+		if field.Tag.Get("mapmode") == "kvcsv" {
+			entries = d.parseKVCSVEntries(values)
+		} else {
+			entries = d.parseMapEntries(values)
+		}
+		entries = append(entries, d.getBracketEntries(fullFieldName)...)
+		if len(entries) == 0 {
+			d.record(fullFieldName, false)
+			return nil
+		}
+		if err := d.setMapEntries(fieldValue, entries); err != nil {
+			return newBindError(field, key, err)
+		}
+		d.record(fullFieldName, true)
+		return nil
+	}
+
+	if fieldValue.Kind() == reflect.Array && !isValueType(fieldValue.Type()) && !implementsJSONUnmarshaler(fieldValue.Type()) {
+		if entries := d.getIndexEntries(fullFieldName); len(entries) > 0 {
+			if err := d.setArrayEntries(fieldValue, values, entries); err != nil {
+				return newBindError(field, key, err)
+			}
+			if err := validateField(field, fieldValue); err != nil {
+				return newBindError(field, key, err)
+			}
+			d.record(fullFieldName, true)
+			return nil
+		}
+	}
+
+	if len(values) == 0 {
+		if d.opts.CheckboxSemantics && fieldValue.Kind() == reflect.Bool {
+			fieldValue.SetBool(false)
+		}
+		d.record(fullFieldName, false)
+		return nil
+	}
+
+	if err := d.setStructField(field, fieldValue, values); err != nil {
+		return newBindError(field, key, err)
+	}
+	if err := validateField(field, fieldValue); err != nil {
+		return newBindError(field, key, err)
+	}
+	d.record(fullFieldName, true)
+
+	return nil
+}
+
+// populateNestedStruct binds a nested struct from a JSON object posted under
+// its key, its dot-notation keys, or both.
+func (d *decoder) populateNestedStruct(field reflect.StructField, structValue reflect.Value, fullFieldName string) error {
+	fromJSON := false
+	if values := d.getFormValues(fullFieldName); len(values) > 0 {
+		value := d.pick(values)
+		if looksLikeJSON(value) {
+			if err := d.unmarshalJSON(value, structValue.Addr().Interface()); err != nil {
+				return newBindError(field, fullFieldName, fmt.Errorf("failed to parse JSON: %w", err))
+			}
+			d.record(fullFieldName, true)
+			if !d.opts.DotOverridesJSON {
+				return nil
+			}
+			fromJSON = true
+		}
+	}
+
+	// Dot-notation keys are applied after any JSON value, so that
+	// settings.theme=light overrides the theme inside settings={...}.
+	if fromJSON && !d.overJSON {
+		d.overJSON = true
+		defer func() { d.overJSON = false }()
+	}
+	return d.populateStruct(structValue, structValue.Type(), fullFieldName)
+}
+
+// isCollectionType reports whether t is a slice, map or array that is bound
+// element by element rather than parsed from a single value.
+func isCollectionType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return !isValueType(t)
+	}
+	return false
+}
+
+// populateCollectionPtr binds a pointer to a slice, map or array through the
+// same lookups as the collection itself, so repeated keys, bracket keys such
+// as labels[key] and JSON values work for *[]T and *map[K]V too. A nil
+// pointer is only allocated once something was bound into it.
+func (d *decoder) populateCollectionPtr(field reflect.StructField, fieldValue reflect.Value, prefix string) error {
+	name, _ := fieldTag(field)
+	if key := d.joinKey(prefix, name); d.isNullSentinel(d.getFormValues(key)) {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		d.record(key, true)
+		return nil
+	}
+
+	if !fieldValue.IsNil() {
+		return d.populateField(field, fieldValue.Elem(), prefix)
+	}
+
+	target := reflect.New(fieldValue.Type().Elem())
+	if err := d.populateField(field, target.Elem(), prefix); err != nil {
+		return err
+	}
+	if !target.Elem().IsZero() {
+		fieldValue.Set(target)
+	}
+	return nil
+}
+
+// isNullSentinel reports whether values hold Options.NullSentinel, asking
+// for a pointer field to be set to nil.
+func (d *decoder) isNullSentinel(values []string) bool {
+	return d.opts.NullSentinel != "" && len(values) > 0 && d.pick(values) == d.opts.NullSentinel
+}
+
+// setterFor returns the SetX(string) error method that Options.UseSetters
+// routes field X of structValue through, or an invalid Value if there is
+// none. Only tagged, exported fields use setters.
+func (d *decoder) setterFor(structValue reflect.Value, field reflect.StructField) reflect.Value {
+	if !d.opts.UseSetters || !field.IsExported() || !structValue.CanAddr() {
+		return reflect.Value{}
+	}
+	if name, _ := fieldTag(field); name == "" || name == "-" || isRequestField(name) {
+		return reflect.Value{}
+	}
+
+	method := structValue.Addr().MethodByName("Set" + field.Name)
+	if !method.IsValid() {
+		return reflect.Value{}
+	}
+	t := method.Type()
+	if t.NumIn() != 1 || t.In(0).Kind() != reflect.String || t.NumOut() != 1 || t.Out(0) != errorType {
+		return reflect.Value{}
+	}
+	return method
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// populateSetter binds field by calling its setter with the posted value
+// instead of assigning it through reflection. The field's validation tags
+// are checked against what the setter stored.
+func (d *decoder) populateSetter(field reflect.StructField, fieldValue, setter reflect.Value, prefix string) error {
+	d.field = field.Name
+	if d.skipField(field) {
+		return nil
+	}
+
+	name, _ := fieldTag(field)
+	key := d.joinKey(prefix, name)
+	values := d.getFieldValues(field, key)
+	if len(values) == 0 {
+		if aliasKey, alias := d.getAliasValues(field, prefix); len(alias) > 0 {
+			key, values = aliasKey, alias
+		}
+	}
+	if len(values) == 0 {
+		if fallbackKey, fallback := d.getFieldNameValues(field, prefix); len(fallback) > 0 {
+			key, values = fallbackKey, fallback
+		}
+	}
+	if len(values) == 0 {
+		values = d.getCookieValues(key)
+	}
+	if d.opts.PreserveOnEmpty && allEmpty(values) {
+		values = nil
+	}
+
+	values, err := d.runFieldHook(field, values)
+	if err != nil {
+		return newBindError(field, key, err)
+	}
+	if len(values) == 0 {
+		d.record(key, false)
+		return nil
+	}
+
+	value := reflect.ValueOf(d.pick(values)).Convert(setter.Type().In(0))
+	if err, _ := setter.Call([]reflect.Value{value})[0].Interface().(error); err != nil {
+		return newBindError(field, key, err)
+	}
+	if err := validateField(field, fieldValue); err != nil {
+		return newBindError(field, key, err)
+	}
+	d.record(key, true)
+	return nil
+}
+
+// skipField reports whether Options.SkipField excludes field from binding.
+func (d *decoder) skipField(field reflect.StructField) bool {
+	return d.opts.SkipField != nil && d.opts.SkipField(field)
+}
+
+// runFieldHook passes values through Options.FieldHook, if one is set. The
+// hook only sees fields that received values.
+func (d *decoder) runFieldHook(field reflect.StructField, values []string) ([]string, error) {
+	if d.opts.FieldHook == nil || len(values) == 0 {
+		return values, nil
+	}
+	return d.opts.FieldHook(field, values)
+}
+
+// allEmpty reports whether every value is the empty string.
+func allEmpty(values []string) bool {
+	for _, v := range values {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// indirect walks v's pointer chain, allocating nil pointers along the way,
+// and returns the value at the end of it.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// getAliasValues looks up field under the keys listed in its aliases tag, in
+// order, for when the primary key is absent. It returns the first alias that
+// has values.
+func (d *decoder) getAliasValues(field reflect.StructField, prefix string) (string, []string) {
+	tag, ok := field.Tag.Lookup("aliases")
+	if !ok {
+		return "", nil
+	}
+
+	for _, alias := range strings.Split(tag, ",") {
+		key := d.joinKey(prefix, strings.TrimSpace(alias))
+		if values := d.getFormValues(key); len(values) > 0 {
+			return key, values
+		}
+	}
+	return "", nil
+}
+
+// getFieldNameValues looks up field by its lowercased Go name when
+// Options.FallbackFieldName is set. It is the last resort after the tag-based
+// keys have come up empty.
+func (d *decoder) getFieldNameValues(field reflect.StructField, prefix string) (string, []string) {
+	if !d.opts.FallbackFieldName {
+		return "", nil
+	}
+
+	key := d.joinKey(prefix, strings.ToLower(field.Name))
+	return key, d.getFormValues(key)
+}
+
+// joinKey builds the form key of a nested field from its parent's key using
+// Options.NestingSeparator, which defaults to ".".
+func (d *decoder) joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + d.separator() + name
+}
+
+// separator returns the nesting separator in effect.
+func (d *decoder) separator() string {
+	return nestingSeparator(d.opts)
+}
+
+// nestingSeparator returns opts.NestingSeparator, or "." when it is empty.
+// Encoding and decoding both use it, so encoded keys read back as written.
+func nestingSeparator(opts Options) string {
+	if opts.NestingSeparator == "" {
+		return "."
+	}
+	return opts.NestingSeparator
+}
+
+func (d *decoder) getFormValues(fieldName string) []string {
+	if !d.opts.StripBOM {
+		return d.lookupFormValues(fieldName)
+	}
+
+	// A BOM at the start of the body ends up in the first key.
+	values := d.lookupFormValues(fieldName)
+	if values == nil {
+		values = d.lookupFormValues(byteOrderMark + fieldName)
+	}
+	return stripBOM(values)
+}
+
+// byteOrderMark is the UTF-8 encoded BOM removed by Options.StripBOM.
+const byteOrderMark = "\uFEFF"
+
+// stripBOM returns values with a leading byte order mark removed from each.
+func stripBOM(values []string) []string {
+	if !slices.ContainsFunc(values, func(v string) bool { return strings.HasPrefix(v, byteOrderMark) }) {
+		return values
+	}
+
+	stripped := make([]string, len(values))
+	for i, value := range values {
+		stripped[i] = strings.TrimPrefix(value, byteOrderMark)
+	}
+	return stripped
+}
+
+// lookupFormValues returns the values posted under fieldName, looking in
+// the form and then the multipart values.
+func (d *decoder) lookupFormValues(fieldName string) []string {
+	key := d.normalizeKey(fieldName)
+	if values, ok := d.form[key]; ok {
+		d.markUsed(key)
+		return values
+	}
+
+	if d.multipartForm != nil {
+		if values, ok := d.multipartForm.Value[key]; ok {
+			d.markUsed(key)
+			return values
+		}
+	}
+
+	return nil
+}
+
+// getCookieValues looks key up among the request's cookies when
+// Options.IncludeCookies is set. Callers try it only after every form-based
+// lookup for a field has come up empty, so form values always win.
+func (d *decoder) getCookieValues(key string) []string {
+	if !d.opts.IncludeCookies || d.request == nil {
+		return nil
+	}
+	if cookie, err := d.request.Cookie(key); err == nil {
+		return []string{cookie.Value}
+	}
+	return nil
+}
+
+// markUsed records that key was read from the form.
+func (d *decoder) markUsed(key string) {
+	if d.used == nil {
+		d.used = make(map[string]bool)
+	}
+	d.used[key] = true
+}
+
+// checkUnknownFields returns an error naming the first form key that no
+// field read, skipping keys listed in Options.IgnoreUnknownFields. File
+// parts are not considered.
+func (d *decoder) checkUnknownFields() error {
+	for _, key := range d.formKeys() {
+		ignored := func(ignore string) bool { return d.normalizeKey(ignore) == key }
+		if !d.used[key] && !slices.ContainsFunc(d.opts.IgnoreUnknownFields, ignored) {
+			return fmt.Errorf("unknown form field %q", key)
+		}
+	}
+	return nil
+}
+
+// setStructField sets the value of a struct field, honoring the field's own
+// parsing tags before falling back to setFieldValue.
+func (d *decoder) setStructField(field reflect.StructField, fieldValue reflect.Value, values []string) error {
+	if isSetMap(field) && fieldValue.Kind() == reflect.Map {
+		return d.setMapSet(fieldValue, values)
+	}
+	if field.Tag.Get("coerce") == "bool" {
+		values = d.coerceBools(values)
+	}
+	if field.Tag.Get("rune") == "char" && len(values) > 0 {
+		return setCharValue(fieldValue, d.pick(values))
+	}
+	if format, ok := field.Tag.Lookup("timeformat"); ok && fieldValue.Type() == timeType && len(values) > 0 {
+		t, err := parseTime(d.pick(values), format)
+		if err != nil {
+			return err
+		}
+		fieldValue.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if field.Tag.Get("durationformat") == "iso8601" && fieldValue.Type() == durationType && len(values) > 0 {
+		duration, err := parseISODuration(d.pick(values))
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(int64(duration))
+		return nil
+	}
+	if field.Tag.Get("numfmt") == "percent" {
+		if err := d.setFieldValue(fieldValue, trimPercentSigns(values)); err != nil {
+			return err
+		}
+		scalePercent(fieldValue)
+		return nil
+	}
+	return d.setFieldValue(fieldValue, values)
+}
+
+// trimPercentSigns drops a trailing "%" from each value, so "45%" parses as
+// the number 45. Only one sign is removed, leaving "45%%" malformed.
+func trimPercentSigns(values []string) []string {
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	}
+	return trimmed
+}
+
+// scalePercent divides the floats in v by 100, turning a percentage into a
+// fraction. It descends into pointers, slices and arrays.
+func scalePercent(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(v.Float() / 100)
+	case reflect.Ptr:
+		if !v.IsNil() {
+			scalePercent(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			scalePercent(v.Index(i))
+		}
+	}
+}
+
+// setCharValue sets a rune field to the code point of a single-character
+// value, or a byte field to the value's first byte.
+func setCharValue(fieldValue reflect.Value, value string) error {
+	switch fieldValue.Kind() {
+	case reflect.Int32:
+		r, size := utf8.DecodeRuneInString(value)
+		if size == 0 || size != len(value) || r == utf8.RuneError {
+			return fmt.Errorf("expected a single character, got %q", value)
+		}
+		fieldValue.SetInt(int64(r))
+
+	case reflect.Uint8:
+		if value == "" {
+			return fmt.Errorf("expected a character, got an empty value")
+		}
+		fieldValue.SetUint(uint64(value[0]))
+
+	default:
+		return fmt.Errorf(`rune:"char" requires a rune or byte field, got %s`, fieldValue.Type())
+	}
+	return nil
+}
+
+func (d *decoder) setFieldValue(fieldValue reflect.Value, values []string) error {
+	fieldType := fieldValue.Type()
+
+	if fieldType.Kind() != reflect.Ptr && implementsFormUnmarshaler(fieldType) && fieldValue.CanAddr() {
+		if len(values) > 0 {
+			return fieldValue.Addr().Interface().(FormUnmarshaler).UnmarshalForm(values)
+		}
+		return nil
+	}
+
+	if parse, ok := valueParsers[fieldType]; ok {
+		if len(values) > 0 {
+			value := d.pick(values)
+			switch fieldType {
+			case bigIntType:
+				value = d.normalizeNumber(value)
+			case bigFloatType:
+				value = d.normalizeFloat(value)
+			}
+			parsed, err := parse(value)
+			if err != nil {
+				return err
+			}
+			fieldValue.Set(reflect.ValueOf(parsed))
+		}
+		return nil
+	}
+
+	if isNullType(fieldType) {
+		if len(values) > 0 {
+			if d.pick(values) == "" {
+				fieldValue.Set(reflect.Zero(fieldType))
+				return nil
+			}
+			if err := d.setFieldValue(fieldValue.Field(0), values); err != nil {
+				return err
 			}
+			fieldValue.Field(1).SetBool(true)
 		}
-
-		if err := setFieldValue(fieldValue, values); err != nil {
-			return fmt.Errorf("failed to set field %s: %w", field.Name, err)
-		}
+		return nil
 	}
 
-	return nil
-}
-
-func getFormValues(r *http.Request, fieldName string) []string {
-	if values, ok := r.Form[fieldName]; ok {
-		return values
+	if fieldType.Kind() != reflect.Ptr && implementsScanner(fieldType) && fieldValue.CanAddr() {
+		if len(values) > 0 {
+			return fieldValue.Addr().Interface().(sql.Scanner).Scan(d.pick(values))
+		}
+		return nil
 	}
 
-	if r.MultipartForm != nil {
-		if values, ok := r.MultipartForm.Value[fieldName]; ok {
-			return values
+	if fieldType.Kind() != reflect.Ptr && implementsJSONUnmarshaler(fieldType) && fieldValue.CanAddr() {
+		if len(values) > 0 {
+			return unmarshalJSONValue(fieldValue.Addr().Interface().(json.Unmarshaler), d.pick(values))
 		}
+		return nil
 	}
 
-	return nil
-}
-
-func setFieldValue(fieldValue reflect.Value, values []string) error {
-	fieldType := fieldValue.Type()
-
 	switch fieldType.Kind() {
 	case reflect.String:
 		if len(values) > 0 {
-			fieldValue.SetString(values[0])
+			value := d.pick(values)
+			if d.opts.UnescapeHTML {
+				value = html.UnescapeString(value)
+			}
+			fieldValue.SetString(value)
 		}
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		if len(values) > 0 {
-			intVal, err := strconv.ParseInt(values[0], 10, fieldType.Bits())
+			intVal, err := strconv.ParseInt(d.normalizeNumber(d.pick(values)), d.intBase(), fieldType.Bits())
 			if err != nil {
 				return err
 			}
@@ -261,7 +1472,7 @@ func setFieldValue(fieldValue reflect.Value, values []string) error {
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if len(values) > 0 {
-			uintVal, err := strconv.ParseUint(values[0], 10, fieldType.Bits())
+			uintVal, err := strconv.ParseUint(d.normalizeNumber(d.pick(values)), d.intBase(), fieldType.Bits())
 			if err != nil {
 				return err
 			}
@@ -270,41 +1481,69 @@ func setFieldValue(fieldValue reflect.Value, values []string) error {
 
 	case reflect.Float32, reflect.Float64:
 		if len(values) > 0 {
-			floatVal, err := strconv.ParseFloat(values[0], fieldType.Bits())
+			floatVal, err := strconv.ParseFloat(d.normalizeFloat(d.pick(values)), fieldType.Bits())
 			if err != nil {
 				return err
 			}
 			fieldValue.SetFloat(floatVal)
 		}
 
+	case reflect.Complex64, reflect.Complex128:
+		if len(values) > 0 {
+			complexVal, err := strconv.ParseComplex(d.pick(values), fieldType.Bits())
+			if err != nil {
+				return err
+			}
+			fieldValue.SetComplex(complexVal)
+		}
+
 	case reflect.Bool:
 		if len(values) > 0 {
-			boolVal, err := strconv.ParseBool(values[0])
+			boolVal, err := d.parseBool(d.pick(values))
 			if err != nil {
-				boolVal = values[0] == "on" || values[0] == "1" || values[0] == "true"
+				return err
 			}
 			fieldValue.SetBool(boolVal)
 		}
 
 	case reflect.Slice:
-		return setSliceValue(fieldValue, values)
+		return d.setSliceValue(fieldValue, values)
 
 	case reflect.Array:
-		return setArrayValue(fieldValue, values)
+		return d.setArrayValue(fieldValue, values)
 
 	case reflect.Map:
-		return setMapValue(fieldValue, values)
+		return d.setMapValue(fieldValue, values)
 
 	case reflect.Ptr:
 		if len(values) > 0 {
 			if fieldValue.IsNil() {
 				fieldValue.Set(reflect.New(fieldType.Elem()))
 			}
-			return setFieldValue(fieldValue.Elem(), values)
+			return d.setFieldValue(fieldValue.Elem(), values)
 		}
 
 	case reflect.Struct:
-		log.Panic("struct fields should be handled in populateStruct")
+		// Struct fields are walked by populateStruct; structs reached here are
+		// slice, map or pointer elements, which can only come from JSON.
+		if len(values) > 0 {
+			value := d.pick(values)
+			if isBlankJSON(value) {
+				return nil
+			}
+			if !looksLikeJSON(value) {
+				return fmt.Errorf("cannot bind %q to %s: expected a JSON object", value, fieldType)
+			}
+			return d.unmarshalJSON(value, fieldValue.Addr().Interface())
+		}
+
+	case reflect.Interface:
+		if fieldType.NumMethod() != 0 {
+			return fmt.Errorf("unsupported field type: %s", fieldType)
+		}
+		if len(values) > 0 {
+			return setInterfaceValue(fieldValue, values)
+		}
 
 	default:
 		return fmt.Errorf("unsupported field type: %s", fieldType.Kind())
@@ -313,15 +1552,159 @@ func setFieldValue(fieldValue reflect.Value, values []string) error {
 	return nil
 }
 
-func setSliceValue(fieldValue reflect.Value, values []string) error {
+// setInterfaceValue stores values in an empty interface field: a single value
+// as a string, or as map[string]any or []any when it looks like JSON, and
+// several values as a []string.
+func setInterfaceValue(fieldValue reflect.Value, values []string) error {
+	if len(values) > 1 {
+		fieldValue.Set(reflect.ValueOf(slices.Clone(values)))
+		return nil
+	}
+
+	value := values[0]
+	if !looksLikeJSON(value) {
+		fieldValue.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return err
+	}
+	fieldValue.Set(reflect.ValueOf(decoded))
+	return nil
+}
+
+// pick returns the value used for a scalar field: the first one posted, or
+// the last one when Options.UseLastValue is set.
+func (d *decoder) pick(values []string) string {
+	if d.opts.UseLastValue {
+		return values[len(values)-1]
+	}
+	return values[0]
+}
+
+// numberSeparatorReplacer removes the grouping separators accepted by
+// Options.StripNumberSeparators.
+var numberSeparatorReplacer = strings.NewReplacer(",", "", " ", "", "\u00a0", "", "\u202f", "")
+
+// decimalCommaSeparatorReplacer is numberSeparatorReplacer for
+// Options.DecimalComma, where the comma marks decimals and dots group
+// thousands, as in "1.234,56".
+var decimalCommaSeparatorReplacer = strings.NewReplacer(".", "", " ", "", "\u00a0", "", "\u202f", "")
+
+// normalizeNumber prepares a numeric form value for strconv, dropping
+// thousands separators when Options.StripNumberSeparators is set.
+func (d *decoder) normalizeNumber(s string) string {
+	if d.opts.StripNumberSeparators {
+		if d.opts.DecimalComma {
+			return decimalCommaSeparatorReplacer.Replace(s)
+		}
+		s = numberSeparatorReplacer.Replace(s)
+	}
+	return s
+}
+
+// normalizeFloat is normalizeNumber for float values, which also turns a
+// decimal comma into a dot when Options.DecimalComma is set.
+func (d *decoder) normalizeFloat(s string) string {
+	s = d.normalizeNumber(s)
+	if d.opts.DecimalComma && strings.Count(s, ",") == 1 {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return s
+}
+
+// coerceBoolWords are the words a field tagged `coerce:"bool"` accepts on top
+// of the values parseBool knows.
+var coerceBoolWords = map[string]string{
+	"yes": "true", "y": "true", "on": "true", "enabled": "true",
+	"no": "false", "n": "false", "off": "false", "disabled": "false",
+}
+
+// coerceBools rewrites common yes/no words in values to "true" or "false",
+// for fields tagged `coerce:"bool"`. Values matching Options.TrueValues or
+// Options.FalseValues are left for parseBool, so custom sets still win.
+func (d *decoder) coerceBools(values []string) []string {
+	coerced := make([]string, len(values))
+	for i, value := range values {
+		coerced[i] = value
+		if d.isCustomBool(value) {
+			continue
+		}
+		if word, ok := coerceBoolWords[strings.ToLower(strings.TrimSpace(value))]; ok {
+			coerced[i] = word
+		}
+	}
+	return coerced
+}
+
+// isCustomBool reports whether s is one of Options.TrueValues or
+// Options.FalseValues.
+func (d *decoder) isCustomBool(s string) bool {
+	return slices.ContainsFunc(d.opts.TrueValues, func(v string) bool { return strings.EqualFold(s, v) }) ||
+		slices.ContainsFunc(d.opts.FalseValues, func(v string) bool { return strings.EqualFold(s, v) })
+}
+
+// parseBool converts a form value to a bool. Options.TrueValues and
+// Options.FalseValues are checked first, case-insensitively, followed by
+// strconv.ParseBool and the checkbox value "on". Without Options.StrictBool,
+// other numbers such as "1.0" are true unless they equal zero, and anything
+// else is false; with it, they are errors.
+func (d *decoder) parseBool(s string) (bool, error) {
+	for _, v := range d.opts.TrueValues {
+		if strings.EqualFold(s, v) {
+			return true, nil
+		}
+	}
+	for _, v := range d.opts.FalseValues {
+		if strings.EqualFold(s, v) {
+			return false, nil
+		}
+	}
+
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b, nil
+	}
+	if s == "on" {
+		return true, nil
+	}
+
+	if d.opts.StrictBool {
+		return false, fmt.Errorf("invalid boolean value %q", s)
+	}
+	if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+		return f != 0, nil
+	}
+	return false, nil
+}
+
+// intBase returns the base passed to strconv when parsing integer fields.
+func (d *decoder) intBase() int {
+	if d.opts.AutoIntBase {
+		return 0
+	}
+	return 10
+}
+
+func (d *decoder) setSliceValue(fieldValue reflect.Value, values []string) error {
 	sliceType := fieldValue.Type()
 
+	if len(values) == 1 && isJSONArray(values[0]) && !isValueType(sliceType.Elem()) {
+		newSlice := reflect.New(sliceType)
+		if err := d.unmarshalJSON(values[0], newSlice.Interface()); err != nil {
+			return err
+		}
+		fieldValue.Set(newSlice.Elem())
+		return nil
+	}
+
 	newSlice := reflect.MakeSlice(sliceType, len(values), len(values))
 
 	for i, value := range values {
 		elem := newSlice.Index(i)
-		if err := setFieldValue(elem, []string{value}); err != nil {
-			return err
+		if err := d.setFieldValue(elem, []string{value}); err != nil {
+			return &indexError{index: i, err: err}
 		}
 	}
 
@@ -329,49 +1712,481 @@ func setSliceValue(fieldValue reflect.Value, values []string) error {
 	return nil
 }
 
-func setArrayValue(fieldValue reflect.Value, values []string) error {
+func (d *decoder) setArrayValue(fieldValue reflect.Value, values []string) error {
+	if fieldValue.Type().Elem().Kind() == reflect.Uint8 && isRawBytes(values) {
+		setByteArray(fieldValue, values[0])
+		return nil
+	}
+
 	arrayLen := fieldValue.Len()
 
 	for i := 0; i < arrayLen && i < len(values); i++ {
 		elem := fieldValue.Index(i)
-		if err := setFieldValue(elem, []string{values[i]}); err != nil {
-			return err
+		if err := d.setFieldValue(elem, []string{values[i]}); err != nil {
+			return &indexError{index: i, err: err}
+		}
+	}
+
+	return nil
+}
+
+// isRawBytes reports whether values posted for a byte array are a token to
+// copy byte for byte: a single value that isn't itself a byte value such as
+// "7". Repeated values, and a lone number, fill the array one element each,
+// as with any other array.
+func isRawBytes(values []string) bool {
+	if len(values) != 1 {
+		return false
+	}
+	_, err := strconv.ParseUint(values[0], 10, 8)
+	return err != nil
+}
+
+// setByteArray copies the raw bytes of value into a byte array such as
+// [16]byte, for fixed-width tokens. Longer values are truncated and shorter
+// ones are padded with zero bytes.
+func setByteArray(fieldValue reflect.Value, value string) {
+	fieldValue.SetZero()
+	reflect.Copy(fieldValue, reflect.ValueOf(value))
+}
+
+// setArrayEntries fills an array from sequential values and then places
+// each indexed entry, such as "scores[4]=99", at its own slot. The entries
+// come from getIndexEntries, so their keys are valid indices; those past the
+// end of the array are ignored.
+func (d *decoder) setArrayEntries(fieldValue reflect.Value, values []string, entries []mapEntry) error {
+	if err := d.setArrayValue(fieldValue, values); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		index, _ := strconv.Atoi(entry.key)
+		if index >= fieldValue.Len() {
+			continue
+		}
+
+		if err := d.setFieldValue(fieldValue.Index(index), []string{entry.value}); err != nil {
+			return &indexError{index: index, err: err}
+		}
+	}
+	return nil
+}
+
+func (d *decoder) setMapValue(fieldValue reflect.Value, values []string) error {
+	if len(values) == 1 && looksLikeJSON(values[0]) {
+		return d.setMapJSON(fieldValue, values[0])
+	}
+	return d.setMapEntries(fieldValue, d.parseMapEntries(values))
+}
+
+// isSetMap reports whether field is a bool map tagged `mapmode:"set"`, which
+// binds each posted value as a key rather than as a "key:value" entry.
+func isSetMap(field reflect.StructField) bool {
+	return field.Tag.Get("mapmode") == "set"
+}
+
+// setMapSet replaces the map in fieldValue with one holding true for each of
+// values, so "perms=read&perms=write" yields {"read": true, "write": true}.
+// Empty values, such as a hidden placeholder input, are skipped.
+func (d *decoder) setMapSet(fieldValue reflect.Value, values []string) error {
+	mapType := fieldValue.Type()
+	newMap := reflect.MakeMap(mapType)
+	present := reflect.ValueOf(true).Convert(mapType.Elem())
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		keyVal := reflect.New(mapType.Key()).Elem()
+		if err := d.setFieldValue(keyVal, []string{value}); err != nil {
+			return fmt.Errorf("invalid map key %q: %w", value, err)
 		}
+		newMap.SetMapIndex(keyVal, present)
+	}
+
+	d.assignMap(fieldValue, newMap)
+	return nil
+}
+
+// unmarshalJSON decodes a JSON value posted for a struct, or for a slice
+// given as a JSON array, into v. With Options.StrictJSONStructs, object keys
+// that match no struct field are an error.
+func (d *decoder) unmarshalJSON(value string, v any) error {
+	if !d.opts.StrictJSONStructs {
+		return json.Unmarshal([]byte(value), v)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after JSON object")
 	}
+	return nil
+}
 
+// setMapJSON replaces the map in fieldValue with one decoded from a JSON
+// object, the map counterpart of the JSON shortcut for structs.
+func (d *decoder) setMapJSON(fieldValue reflect.Value, value string) error {
+	newMap := reflect.New(fieldValue.Type())
+	if err := json.Unmarshal([]byte(value), newMap.Interface()); err != nil {
+		return err
+	}
+	d.assignMap(fieldValue, newMap.Elem())
 	return nil
 }
 
-func setMapValue(fieldValue reflect.Value, values []string) error {
+// assignMap stores newMap in fieldValue. When merging, its entries are
+// added to the existing map instead, leaving other keys in place.
+func (d *decoder) assignMap(fieldValue, newMap reflect.Value) {
+	if !d.merge || fieldValue.IsNil() {
+		fieldValue.Set(newMap)
+		return
+	}
+
+	iter := newMap.MapRange()
+	for iter.Next() {
+		fieldValue.SetMapIndex(iter.Key(), iter.Value())
+	}
+}
+
+// setMapEntries replaces the map in fieldValue with one built from entries.
+// When the map's element type is a slice, repeated keys accumulate into that
+// slice instead of overwriting each other.
+func (d *decoder) setMapEntries(fieldValue reflect.Value, entries []mapEntry) error {
 	mapType := fieldValue.Type()
 	keyType := mapType.Key()
 	valueType := mapType.Elem()
 
 	newMap := reflect.MakeMap(mapType)
 
-	for _, value := range values {
-		parts := strings.SplitN(value, ":", 2)
-		if len(parts) != 2 {
+	for _, entry := range entries {
+		keyVal := reflect.New(keyType).Elem()
+		if err := d.setFieldValue(keyVal, []string{entry.key}); err != nil {
+			return fmt.Errorf("invalid map key %q: %w", entry.key, err)
+		}
+
+		if entry.bare {
+			if !newMap.MapIndex(keyVal).IsValid() {
+				newMap.SetMapIndex(keyVal, reflect.Zero(valueType))
+			}
 			continue
 		}
 
-		keyVal := reflect.New(keyType).Elem()
-		if err := setFieldValue(keyVal, []string{parts[0]}); err != nil {
+		var valVal reflect.Value
+		if valueType.Kind() == reflect.Slice {
+			elem := reflect.New(valueType.Elem()).Elem()
+			if err := d.setFieldValue(elem, []string{entry.value}); err != nil {
+				return fmt.Errorf("invalid value for map key %q: %w", entry.key, err)
+			}
+
+			valVal = newMap.MapIndex(keyVal)
+			if !valVal.IsValid() {
+				valVal = reflect.MakeSlice(valueType, 0, 1)
+			}
+			valVal = reflect.Append(valVal, elem)
+		} else {
+			valVal = reflect.New(valueType).Elem()
+			if err := d.setFieldValue(valVal, []string{entry.value}); err != nil {
+				return fmt.Errorf("invalid value for map key %q: %w", entry.key, err)
+			}
+		}
+
+		newMap.SetMapIndex(keyVal, valVal)
+	}
+
+	d.assignMap(fieldValue, newMap)
+	return nil
+}
+
+// setStructMap replaces the map in fieldValue with one holding a struct for
+// each of mapKeys, populated from keys such as "addresses[home].city".
+func (d *decoder) setStructMap(fieldValue reflect.Value, fieldName string, mapKeys []string) error {
+	mapType := fieldValue.Type()
+	newMap := reflect.MakeMap(mapType)
+
+	for _, mapKey := range mapKeys {
+		keyVal := reflect.New(mapType.Key()).Elem()
+		if err := d.setFieldValue(keyVal, []string{mapKey}); err != nil {
+			return fmt.Errorf("invalid map key %q: %w", mapKey, err)
+		}
+
+		elem := reflect.New(mapType.Elem()).Elem()
+		if d.merge && !fieldValue.IsNil() {
+			if existing := fieldValue.MapIndex(keyVal); existing.IsValid() {
+				elem.Set(existing)
+			}
+		}
+
+		target := indirect(elem)
+		if err := d.populateStruct(target, target.Type(), fieldName+"["+mapKey+"]"); err != nil {
 			return err
 		}
 
-		valVal := reflect.New(valueType).Elem()
-		if err := setFieldValue(valVal, []string{parts[1]}); err != nil {
+		newMap.SetMapIndex(keyVal, elem)
+	}
+
+	d.assignMap(fieldValue, newMap)
+	return nil
+}
+
+// setStructArray populates the struct elements of an array from keys such as
+// "addresses[0].city". Indices past the end of the array are ignored.
+func (d *decoder) setStructArray(fieldValue reflect.Value, fieldName string, indexKeys []string) error {
+	for _, indexKey := range indexKeys {
+		index, err := strconv.Atoi(indexKey)
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid index %q", indexKey)
+		}
+		if index >= fieldValue.Len() {
+			continue
+		}
+
+		elem := indirect(fieldValue.Index(index))
+		if err := d.populateStruct(elem, elem.Type(), fieldName+"["+indexKey+"]"); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		newMap.SetMapIndex(keyVal, valVal)
+// setStructSlice replaces the slice in fieldValue with one element per
+// index posted as name[i].field, in index order. Gaps between indices are
+// closed, so a form whose rows were removed client-side still binds densely
+// and a large index can't force a large allocation. Pointer elements are
+// allocated.
+func (d *decoder) setStructSlice(fieldValue reflect.Value, fieldName string, indexKeys []string) error {
+	indices := make([]int, len(indexKeys))
+	byIndex := make(map[int]string, len(indexKeys))
+	for i, indexKey := range indexKeys {
+		index, err := strconv.Atoi(indexKey)
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid index %q", indexKey)
+		}
+		if prev, ok := byIndex[index]; ok {
+			return fmt.Errorf("index %q repeats %q", indexKey, prev)
+		}
+		indices[i] = index
+		byIndex[index] = indexKey
 	}
+	slices.Sort(indices)
 
-	fieldValue.Set(newMap)
+	newSlice := reflect.MakeSlice(fieldValue.Type(), len(indices), len(indices))
+	for i, index := range indices {
+		elem := indirect(newSlice.Index(i))
+		if err := d.populateStruct(elem, elem.Type(), fieldName+"["+byIndex[index]+"]"); err != nil {
+			return err
+		}
+	}
+	fieldValue.Set(newSlice)
 	return nil
 }
 
+// getBracketStructKeys returns the distinct map keys posted for a map of
+// structs, e.g. "home" and "work" for "addresses[home].city" and
+// "addresses[work].street". Arrays of structs use it to find their indices.
+func (d *decoder) getBracketStructKeys(fieldName string) []string {
+	keyPrefix := d.normalizeKey(fieldName) + "["
+	sep := d.separator()
+
+	seen := make(map[string]bool)
+	var mapKeys []string
+	for _, formKey := range d.formKeys() {
+		rest, ok := strings.CutPrefix(formKey, keyPrefix)
+		if !ok {
+			continue
+		}
+
+		mapKey, subKey, ok := strings.Cut(rest, "]")
+		if !ok || !strings.HasPrefix(subKey, sep) || seen[mapKey] {
+			continue
+		}
+
+		seen[mapKey] = true
+		mapKeys = append(mapKeys, mapKey)
+	}
+	return mapKeys
+}
+
+// isStructElem reports whether t, after following pointers, is a struct
+// that binds field by field.
+func isStructElem(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isValueType(t)
+}
+
+// mapEntry is a single key/value pair destined for a map field. A bare entry
+// came from a value without a delimiter and stands for the element type's
+// zero value.
+type mapEntry struct {
+	key   string
+	value string
+	bare  bool
+}
+
+// parseMapEntries splits "key:value" form values into map entries. The value
+// is everything after the first colon, so "flag:" yields an empty value
+// rather than a missing one. Values without a colon are dropped unless
+// Options.MapBareKeyZero is set, in which case they become bare entries.
+func (d *decoder) parseMapEntries(values []string) []mapEntry {
+	entries := make([]mapEntry, 0, len(values))
+	for _, value := range values {
+		key, val, found := strings.Cut(value, ":")
+		if !found {
+			if d.opts.MapBareKeyZero {
+				entries = append(entries, mapEntry{key: key, bare: true})
+			}
+			continue
+		}
+		entries = append(entries, mapEntry{key: key, value: val})
+	}
+	return entries
+}
+
+// parseKVCSVEntries splits values such as "color=red,size=large" into map
+// entries, for fields tagged `mapmode:"kvcsv"`. Pairs are separated by commas
+// and split at their first "=", so "q=a=b" yields the value "a=b". Empty
+// pairs are skipped, and pairs without "=" are handled as in
+// parseMapEntries.
+func (d *decoder) parseKVCSVEntries(values []string) []mapEntry {
+	var entries []mapEntry
+	for _, value := range values {
+		for _, pair := range strings.Split(value, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			key, val, found := strings.Cut(pair, "=")
+			if !found {
+				if d.opts.MapBareKeyZero {
+					entries = append(entries, mapEntry{key: key, bare: true})
+				}
+				continue
+			}
+			entries = append(entries, mapEntry{key: key, value: val})
+		}
+	}
+	return entries
+}
+
+// getBracketEntries collects map entries posted with bracket notation, e.g.
+// "filters[color]=red" for the field name "filters".
+func (d *decoder) getBracketEntries(fieldName string) []mapEntry {
+	return d.collectBracketEntries(fieldName, func(string) bool { return true })
+}
+
+// getIndexEntries collects the bracket entries of an array whose key is an
+// index, such as "scores[4]=99". Other keys, including the "scores[]" that
+// PHP-style forms post for every value, are ignored.
+func (d *decoder) getIndexEntries(fieldName string) []mapEntry {
+	return d.collectBracketEntries(fieldName, func(key string) bool {
+		index, err := strconv.Atoi(key)
+		return err == nil && index >= 0
+	})
+}
+
+// collectBracketEntries collects the entries posted as fieldName[key] for
+// the keys that accept allows.
+func (d *decoder) collectBracketEntries(fieldName string, accept func(key string) bool) []mapEntry {
+	keyPrefix := d.normalizeKey(fieldName) + "["
+
+	var entries []mapEntry
+	for _, formKey := range d.formKeys() {
+		if !strings.HasPrefix(formKey, keyPrefix) || !strings.HasSuffix(formKey, "]") {
+			continue
+		}
+
+		mapKey := formKey[len(keyPrefix) : len(formKey)-1]
+		if strings.ContainsAny(mapKey, "[]") || !accept(mapKey) {
+			continue
+		}
+
+		for _, value := range d.getFormValues(formKey) {
+			entries = append(entries, mapEntry{key: mapKey, value: value})
+		}
+	}
+	return entries
+}
+
+// normalizeKey applies Options.NormalizeKeys to key, if set.
+func (d *decoder) normalizeKey(key string) string {
+	if d.opts.NormalizeKeys == nil {
+		return key
+	}
+	return d.opts.NormalizeKeys(key)
+}
+
+// normalizeForm replaces the decoder's form and multipart values with copies
+// whose keys went through Options.NormalizeKeys. The request's own maps are
+// left as they were.
+func (d *decoder) normalizeForm() {
+	d.form = normalizeValues(d.form, d.opts.NormalizeKeys)
+	if d.multipartForm == nil {
+		return
+	}
+
+	files := make(map[string][]*multipart.FileHeader, len(d.multipartForm.File))
+	for _, key := range slices.Sorted(maps.Keys(d.multipartForm.File)) {
+		normalized := d.opts.NormalizeKeys(key)
+		files[normalized] = append(files[normalized], d.multipartForm.File[key]...)
+	}
+	d.multipartForm = &multipart.Form{
+		Value: normalizeValues(d.multipartForm.Value, d.opts.NormalizeKeys),
+		File:  files,
+	}
+}
+
+// normalizeValues returns a copy of values with normalize applied to every
+// key. Values of keys that normalize alike are combined in key order.
+func normalizeValues(values map[string][]string, normalize func(string) string) url.Values {
+	normalized := make(url.Values, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		k := normalize(key)
+		normalized[k] = append(normalized[k], values[key]...)
+	}
+	return normalized
+}
+
+// formKeys returns every key present in the parsed form, sorted so that
+// lookups scanning the whole form behave deterministically.
+func (d *decoder) formKeys() []string {
+	seen := make(map[string]bool, len(d.form))
+	keys := make([]string, 0, len(d.form))
+	for key := range d.form {
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	if d.multipartForm != nil {
+		for key := range d.multipartForm.Value {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// isBlankJSON reports whether a value posted for a struct carries no data:
+// either nothing at all or a JSON null. Such values leave the struct zero.
+func isBlankJSON(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s == "null"
+}
+
+// isJSONArray reports whether s is a well-formed JSON array, which a slice
+// field decodes as a whole instead of as a single element. Values that only
+// look like one, such as "[draft]", are left to bind as plain text.
+func isJSONArray(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "[") && json.Valid([]byte(s))
+}
+
 func looksLikeJSON(s string) bool {
 	s = strings.TrimSpace(s)
 	return (strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")) ||
@@ -390,3 +2205,24 @@ func GetFile(r *http.Request, fieldName string) (multipart.File, *multipart.File
 
 	return file, header, nil
 }
+
+// HasField reports whether the form posted a value under name, even an empty
+// one. It looks in r.Form and then in the multipart values, the same lookup
+// Populate uses, so the request must have been parsed already, for example by
+// an earlier Populate or r.ParseMultipartForm.
+func HasField(r *http.Request, name string) bool {
+	_, ok := FieldValue(r, name)
+	return ok
+}
+
+// FieldValue returns the first value posted under name and whether there was
+// one. Like HasField it reads the already parsed form, including multipart
+// values.
+func FieldValue(r *http.Request, name string) (string, bool) {
+	d := &decoder{form: r.Form, multipartForm: r.MultipartForm}
+	values := d.getFormValues(name)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
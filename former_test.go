@@ -2,15 +2,30 @@ package former
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/mail"
+	"net/netip"
+	"net/textproto"
 	"net/url"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 type BasicTypes struct {
@@ -348,7 +363,7 @@ func TestPopulate_ComplexNestedStructs(t *testing.T) {
 		Bio:     "Software developer",
 		Hobbies: []string{"coding", "reading", "gaming"},
 	}
-	expected.Settings.Theme = "dark"
+	expected.Settings.Theme = "light"
 	expected.Settings.Language = "en"
 
 	if result.Bio != expected.Bio {
@@ -357,11 +372,58 @@ func TestPopulate_ComplexNestedStructs(t *testing.T) {
 	if !reflect.DeepEqual(result.Hobbies, expected.Hobbies) {
 		t.Errorf("Hobbies: got %v, want %v", result.Hobbies, expected.Hobbies)
 	}
-	if result.Settings.Theme != "dark" {
-		t.Errorf("Settings.Theme: got %v, want 'dark'", result.Settings.Theme)
+	if result.Settings != expected.Settings {
+		t.Errorf("Settings: got %+v, want %+v", result.Settings, expected.Settings)
 	}
 }
 
+func TestPopulateWithOptions_DotOverridesJSON(t *testing.T) {
+	formData := url.Values{
+		"settings":       {`{"theme":"dark","lang":"en"}`},
+		"settings.theme": {"light"},
+	}
+
+	tests := []struct {
+		name             string
+		dotOverridesJSON bool
+		wantTheme        string
+	}{
+		{name: "dot keys win", dotOverridesJSON: true, wantTheme: "light"},
+		{name: "JSON wins", dotOverridesJSON: false, wantTheme: "dark"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			opts := DefaultOptions()
+			opts.DotOverridesJSON = tt.dotOverridesJSON
+
+			var result Profile
+			if err := PopulateWithOptions(req, &result, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Settings.Theme != tt.wantTheme || result.Settings.Language != "en" {
+				t.Errorf("Settings: got %+v, want theme %q and language en", result.Settings, tt.wantTheme)
+			}
+		})
+	}
+
+	t.Run("dot keys still apply without JSON", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?settings.theme=light", nil)
+		opts := DefaultOptions()
+		opts.DotOverridesJSON = false
+
+		var result Profile
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Settings.Theme != "light" {
+			t.Errorf("Theme = %q, want light", result.Settings.Theme)
+		}
+	})
+}
+
 func TestPopulate_MultipartForm(t *testing.T) {
 	var b bytes.Buffer
 	w := multipart.NewWriter(&b)
@@ -866,3 +928,5580 @@ func TestLooksLikeJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestPopulateWithOptions_AutoIntBase(t *testing.T) {
+	type Numbers struct {
+		Int  int   `formfield:"int"`
+		Uint uint  `formfield:"uint"`
+		I64  int64 `formfield:"i64"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Numbers
+	}{
+		{
+			name:     "hexadecimal",
+			formData: url.Values{"int": {"0xFF"}, "uint": {"0x1f"}, "i64": {"-0x10"}},
+			expected: Numbers{Int: 255, Uint: 31, I64: -16},
+		},
+		{
+			name:     "octal",
+			formData: url.Values{"int": {"0o17"}, "uint": {"017"}},
+			expected: Numbers{Int: 15, Uint: 15},
+		},
+		{
+			name:     "binary",
+			formData: url.Values{"int": {"0b101"}, "uint": {"0b11"}},
+			expected: Numbers{Int: 5, Uint: 3},
+		},
+		{
+			name:     "plain decimal",
+			formData: url.Values{"int": {"42"}, "uint": {"7"}},
+			expected: Numbers{Int: 42, Uint: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Numbers
+			err := PopulateWithOptions(req, &result, Options{AutoIntBase: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("base 10 by default", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("int=0xFF"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Numbers
+		if err := Populate(req, &result); err == nil {
+			t.Errorf("expected error for hex input without AutoIntBase, got %+v", result)
+		}
+
+		req = httptest.NewRequest("POST", "/", strings.NewReader("int=08"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Int != 8 {
+			t.Errorf("expected 8, got %d", result.Int)
+		}
+	})
+}
+
+func TestPopulate_ComplexNumbers(t *testing.T) {
+	type Numbers struct {
+		C64  complex64  `formfield:"c64"`
+		C128 complex128 `formfield:"c128"`
+	}
+
+	t.Run("valid complex values", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("c64=1%2B2i&c128=-3.5-0.5i"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Numbers
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := Numbers{C64: 1 + 2i, C128: -3.5 - 0.5i}
+		if result != expected {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("invalid complex value", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("c128=one+two"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Numbers
+		err := Populate(req, &result)
+		if err == nil {
+			t.Fatal("expected error for invalid complex value")
+		}
+		if !strings.Contains(err.Error(), "failed to set field C128") {
+			t.Errorf("error = %v, should mention the field", err)
+		}
+	})
+}
+
+func TestPopulate_MultiValueMaps(t *testing.T) {
+	type Filters struct {
+		Filters map[string][]string `formfield:"filters"`
+		Counts  map[string][]int    `formfield:"counts"`
+		Plain   map[string]string   `formfield:"plain"`
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected Filters
+	}{
+		{
+			name: "colon form",
+			body: "filters=color:red&filters=color:blue&filters=size:L&counts=a:1&counts=a:2",
+			expected: Filters{
+				Filters: map[string][]string{"color": {"red", "blue"}, "size": {"L"}},
+				Counts:  map[string][]int{"a": {1, 2}},
+			},
+		},
+		{
+			name: "bracket form",
+			body: "filters[color]=red&filters[color]=blue&filters[size]=L&plain[theme]=dark",
+			expected: Filters{
+				Filters: map[string][]string{"color": {"red", "blue"}, "size": {"L"}},
+				Plain:   map[string]string{"theme": "dark"},
+			},
+		},
+		{
+			name: "mixed forms",
+			body: "filters=color:red&filters[color]=blue",
+			expected: Filters{
+				Filters: map[string][]string{"color": {"red", "blue"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Filters
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("invalid slice element", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("counts[a]=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Filters
+		if err := Populate(req, &result); err == nil {
+			t.Error("expected error for invalid map element")
+		}
+	})
+}
+
+func TestPopulate_PointerChains(t *testing.T) {
+	type Inner struct {
+		Value string `formfield:"value"`
+	}
+	type Outer struct {
+		Name  **string `formfield:"name"`
+		Inner **Inner  `formfield:"inner"`
+		Count ***int   `formfield:"count"`
+	}
+
+	t.Run("values present", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=deep&inner.value=nested&count=3"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Outer
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Name == nil || *result.Name == nil || **result.Name != "deep" {
+			t.Errorf("Name: expected 'deep', got %v", result.Name)
+		}
+		if result.Inner == nil || *result.Inner == nil || (*result.Inner).Value != "nested" {
+			t.Errorf("Inner: expected value 'nested', got %v", result.Inner)
+		}
+		if result.Count == nil || *result.Count == nil || **result.Count == nil || ***result.Count != 3 {
+			t.Errorf("Count: expected 3, got %v", result.Count)
+		}
+	})
+
+	t.Run("values absent", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("other=value"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Outer
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Name != nil || result.Inner != nil || result.Count != nil {
+			t.Errorf("expected nil pointers, got %+v", result)
+		}
+	})
+}
+
+func TestPopulate_NetworkTypes(t *testing.T) {
+	type Endpoint struct {
+		URL     url.URL      `formfield:"url"`
+		URLPtr  *url.URL     `formfield:"urlptr"`
+		IP      net.IP       `formfield:"ip"`
+		Network net.IPNet    `formfield:"network"`
+		Addr    netip.Addr   `formfield:"addr"`
+		Prefix  netip.Prefix `formfield:"prefix"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		formData := url.Values{
+			"url":     {"https://example.com/path?q=1"},
+			"urlptr":  {"http://localhost:8080"},
+			"ip":      {"192.168.1.10"},
+			"network": {"10.0.0.0/8"},
+			"addr":    {"::1"},
+			"prefix":  {"fd00::/8"},
+		}
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Endpoint
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.URL.Host != "example.com" || result.URL.Path != "/path" || result.URL.RawQuery != "q=1" {
+			t.Errorf("URL: got %v", result.URL.String())
+		}
+		if result.URLPtr == nil || result.URLPtr.Host != "localhost:8080" {
+			t.Errorf("URLPtr: got %v", result.URLPtr)
+		}
+		if !result.IP.Equal(net.ParseIP("192.168.1.10")) {
+			t.Errorf("IP: got %v", result.IP)
+		}
+		if result.Network.String() != "10.0.0.0/8" {
+			t.Errorf("Network: got %v", result.Network.String())
+		}
+		if result.Addr != netip.MustParseAddr("::1") {
+			t.Errorf("Addr: got %v", result.Addr)
+		}
+		if result.Prefix != netip.MustParsePrefix("fd00::/8") {
+			t.Errorf("Prefix: got %v", result.Prefix)
+		}
+	})
+
+	invalid := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"invalid url", "url", "http://[::1"},
+		{"invalid url pointer", "urlptr", "://missing-scheme"},
+		{"invalid ip", "ip", "999.1.1.1"},
+		{"invalid network", "network", "10.0.0.0/99"},
+		{"invalid addr", "addr", "not-an-ip"},
+		{"invalid prefix", "prefix", "fd00::"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			formData := url.Values{tt.key: {tt.value}}
+			req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Endpoint
+			err := Populate(req, &result)
+			if err == nil {
+				t.Fatalf("expected error for %s=%q", tt.key, tt.value)
+			}
+			if !strings.Contains(err.Error(), "failed to set field") {
+				t.Errorf("error = %v, should contain 'failed to set field'", err)
+			}
+		})
+	}
+}
+
+func TestPopulate_MailAddress(t *testing.T) {
+	type Form struct {
+		From mail.Address  `formfield:"from"`
+		To   *mail.Address `formfield:"to"`
+	}
+
+	t.Run("valid addresses", func(t *testing.T) {
+		formData := url.Values{
+			"from": {"Jane <jane@x.com>"},
+			"to":   {"john@example.com"},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.From != (mail.Address{Name: "Jane", Address: "jane@x.com"}) {
+			t.Errorf("From: got %+v", result.From)
+		}
+		if result.To == nil || *result.To != (mail.Address{Address: "john@example.com"}) {
+			t.Errorf("To: got %+v", result.To)
+		}
+	})
+
+	t.Run("malformed address", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?from="+url.QueryEscape("Jane <jane@"), nil)
+
+		var result Form
+		err := Populate(req, &result)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "From" {
+			t.Errorf("got %v, want a BindError for From", err)
+		}
+	})
+}
+
+func TestPopulateWithOptions_FallbackFieldName(t *testing.T) {
+	type Inner struct {
+		Label string `formfield:"label"`
+	}
+	type Form struct {
+		Email    string  `formfield:"email_address"`
+		Age      *int    `formfield:"user_age"`
+		Nickname string  `formfield:"nick"`
+		Inner    Inner   `formfield:"inner"`
+		Extra    *string `formfield:"extra"`
+	}
+
+	body := "email=jane@example.com&age=30&nick=jj&nickname=ignored&inner.label=nested"
+
+	t.Run("enabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, Options{FallbackFieldName: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Email != "jane@example.com" {
+			t.Errorf("Email: got %q, want 'jane@example.com'", result.Email)
+		}
+		if result.Age == nil || *result.Age != 30 {
+			t.Errorf("Age: got %v, want 30", result.Age)
+		}
+		if result.Nickname != "jj" {
+			t.Errorf("Nickname: tag key should win, got %q", result.Nickname)
+		}
+		if result.Inner.Label != "nested" {
+			t.Errorf("Inner.Label: got %q, want 'nested'", result.Inner.Label)
+		}
+		if result.Extra != nil {
+			t.Errorf("Extra: expected nil, got %v", *result.Extra)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Email != "" || result.Age != nil {
+			t.Errorf("expected field-name keys to be ignored, got %+v", result)
+		}
+	})
+}
+
+func TestPopulate_InlineStructs(t *testing.T) {
+	type InlineOrder struct {
+		ID       string  `formfield:"id"`
+		Shipping Address `formfield:",inline"`
+	}
+	type DottedOrder struct {
+		ID       string  `formfield:"id"`
+		Shipping Address `formfield:"shipping"`
+	}
+
+	formData := url.Values{
+		"id":              {"42"},
+		"street":          {"1 Flat St"},
+		"city":            {"Flatville"},
+		"shipping.street": {"2 Dot Ave"},
+		"shipping.city":   {"Dotton"},
+	}
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	var inline InlineOrder
+	if err := Populate(newRequest(), &inline); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedInline := InlineOrder{ID: "42", Shipping: Address{Street: "1 Flat St", City: "Flatville"}}
+	if inline != expectedInline {
+		t.Errorf("inline: got %+v, want %+v", inline, expectedInline)
+	}
+
+	var dotted DottedOrder
+	if err := Populate(newRequest(), &dotted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedDotted := DottedOrder{ID: "42", Shipping: Address{Street: "2 Dot Ave", City: "Dotton"}}
+	if dotted != expectedDotted {
+		t.Errorf("dotted: got %+v, want %+v", dotted, expectedDotted)
+	}
+
+	t.Run("encode round trip", func(t *testing.T) {
+		values, err := Encode(expectedInline)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if values.Get("street") != "1 Flat St" || values.Has("shipping.street") {
+			t.Errorf("expected inline keys, got %v", values)
+		}
+	})
+}
+
+func TestPopulateWithOptions_CustomBoolValues(t *testing.T) {
+	type Form struct {
+		Subscribe bool `formfield:"subscribe"`
+	}
+
+	opts := Options{
+		TrueValues:  []string{"yes", "y", "enabled"},
+		FalseValues: []string{"no", "n", "disabled"},
+	}
+
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"yes", true},
+		{"YES", true},
+		{"Y", true},
+		{"enabled", true},
+		{"no", false},
+		{"No", false},
+		{"disabled", false},
+		{"true", true},
+		{"on", true},
+		{"0", false},
+		{"1.0", true},
+		{"0.0", false},
+		{"-0.00", false},
+		{"2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader("subscribe="+tt.value))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			result := Form{Subscribe: !tt.expected}
+			if err := PopulateWithOptions(req, &result, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Subscribe != tt.expected {
+				t.Errorf("got %v, want %v", result.Subscribe, tt.expected)
+			}
+		})
+	}
+
+	t.Run("unknown value is false by default", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("subscribe=maybe"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		result := Form{Subscribe: true}
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Subscribe {
+			t.Error("expected false for unknown value")
+		}
+	})
+
+	t.Run("unknown value errors in strict mode", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("subscribe=maybe"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		strict := opts
+		strict.StrictBool = true
+
+		var result Form
+		err := PopulateWithOptions(req, &result, strict)
+		if err == nil || !strings.Contains(err.Error(), `invalid boolean value "maybe"`) {
+			t.Errorf("expected invalid boolean error, got %v", err)
+		}
+	})
+
+	t.Run("numeric values error in strict mode", func(t *testing.T) {
+		strict := opts
+		strict.StrictBool = true
+
+		for _, value := range []string{"1.0", "0.0"} {
+			req := httptest.NewRequest("POST", "/", strings.NewReader("subscribe="+value))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := PopulateWithOptions(req, &result, strict)
+			if err == nil || !strings.Contains(err.Error(), "invalid boolean value") {
+				t.Errorf("%s: expected invalid boolean error, got %v", value, err)
+			}
+		}
+	})
+}
+
+func TestPopulateWithOptions_MapBareKeyZero(t *testing.T) {
+	type Form struct {
+		Flags  map[string]string `formfield:"flags"`
+		Counts map[string]int    `formfield:"counts"`
+	}
+
+	body := "flags=flag&flags=empty:&flags=key:value:with:colons&counts=seen&counts=total:3&counts=total"
+
+	t.Run("enabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, Options{MapBareKeyZero: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := Form{
+			Flags:  map[string]string{"flag": "", "empty": "", "key": "value:with:colons"},
+			Counts: map[string]int{"seen": 0, "total": 3},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := Form{
+			Flags:  map[string]string{"empty": "", "key": "value:with:colons"},
+			Counts: map[string]int{"total": 3},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+}
+
+func TestPopulate_SliceOfPointers(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+		Qty  int    `json:"qty"`
+	}
+	type Form struct {
+		Names []*string `formfield:"names"`
+		Nums  []*int    `formfield:"nums"`
+		Items []*Item   `formfield:"items"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		formData := url.Values{
+			"names": {"a", "b", "c"},
+			"nums":  {"1", "2"},
+			"items": {`{"name":"pen","qty":2}`, `{"name":"ink","qty":1}`},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(result.Names) != 3 {
+			t.Fatalf("Names: expected 3 elements, got %d", len(result.Names))
+		}
+		for i, want := range []string{"a", "b", "c"} {
+			if result.Names[i] == nil || *result.Names[i] != want {
+				t.Errorf("Names[%d]: got %v, want %q", i, result.Names[i], want)
+			}
+		}
+		if result.Names[0] == result.Names[1] {
+			t.Error("expected each element to have its own pointer")
+		}
+
+		if len(result.Nums) != 2 || *result.Nums[0] != 1 || *result.Nums[1] != 2 {
+			t.Errorf("Nums: got %v", result.Nums)
+		}
+
+		expectedItems := []*Item{{Name: "pen", Qty: 2}, {Name: "ink", Qty: 1}}
+		if !reflect.DeepEqual(result.Items, expectedItems) {
+			t.Errorf("Items: got %+v, want %+v", result.Items, expectedItems)
+		}
+	})
+
+	t.Run("invalid element reports its index", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("nums=1&nums=2&nums=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), "nums[2]") {
+			t.Errorf("expected error mentioning nums[2], got %v", err)
+		}
+	})
+
+	t.Run("non-JSON struct element", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("items=pen"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), "expected a JSON object") {
+			t.Errorf("expected JSON error, got %v", err)
+		}
+	})
+}
+
+// jsonLevel implements only json.Unmarshaler, accepting either a level name
+// or its numeric value.
+type jsonLevel int
+
+func (l *jsonLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		*l = jsonLevel(n)
+		return nil
+	}
+
+	switch name {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", name)
+	}
+	return nil
+}
+
+// jsonLabels is a named map type that decodes itself from a JSON object.
+type jsonLabels map[string]string
+
+func (l *jsonLabels) UnmarshalJSON(data []byte) error {
+	m := map[string]string{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*l = jsonLabels(m)
+	return nil
+}
+
+func TestPopulate_JSONUnmarshaler(t *testing.T) {
+	type Form struct {
+		Level    jsonLevel   `formfield:"level"`
+		LevelPtr *jsonLevel  `formfield:"levelptr"`
+		Levels   []jsonLevel `formfield:"levels"`
+		Labels   jsonLabels  `formfield:"labels"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		formData := url.Values{
+			"level":    {"high"},
+			"levelptr": {"7"},
+			"levels":   {"low", "high"},
+			"labels":   {`{"env":"prod"}`},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Level != 2 {
+			t.Errorf("Level: got %d, want 2", result.Level)
+		}
+		if result.LevelPtr == nil || *result.LevelPtr != 7 {
+			t.Errorf("LevelPtr: got %v, want 7", result.LevelPtr)
+		}
+		if !reflect.DeepEqual(result.Levels, []jsonLevel{1, 2}) {
+			t.Errorf("Levels: got %v", result.Levels)
+		}
+		if !reflect.DeepEqual(result.Labels, jsonLabels{"env": "prod"}) {
+			t.Errorf("Labels: got %v", result.Labels)
+		}
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("level=medium"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), `unknown level "medium"`) {
+			t.Errorf("expected unmarshal error, got %v", err)
+		}
+	})
+}
+
+func TestPopulateWithOptions_FlattenEmbedded(t *testing.T) {
+	type Audit struct {
+		CreatedBy string `formfield:"created_by"`
+	}
+	type Form struct {
+		Name string `formfield:"name"`
+		Audit
+		Address `formfield:"address"`
+		Contact `formfield:"-"`
+	}
+
+	formData := url.Values{
+		"name":           {"Jane"},
+		"created_by":     {"attacker"},
+		"street":         {"flat street"},
+		"address.street": {"tagged street"},
+		"phone":          {"555"},
+	}
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("enabled by default", func(t *testing.T) {
+		var result Form
+		if err := Populate(newRequest(), &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.CreatedBy != "attacker" {
+			t.Errorf("CreatedBy: got %q, want 'attacker'", result.CreatedBy)
+		}
+		if result.Street != "tagged street" {
+			t.Errorf("Street: got %q, want 'tagged street'", result.Street)
+		}
+		if result.Phone != "" {
+			t.Errorf("Phone: expected skipped embedded struct, got %q", result.Phone)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.FlattenEmbedded = false
+
+		var result Form
+		if err := PopulateWithOptions(newRequest(), &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Name != "Jane" {
+			t.Errorf("Name: got %q, want 'Jane'", result.Name)
+		}
+		if result.CreatedBy != "" {
+			t.Errorf("CreatedBy: expected untagged embedded struct to be ignored, got %q", result.CreatedBy)
+		}
+		if result.Street != "tagged street" {
+			t.Errorf("Street: tagged embedded struct should still bind, got %q", result.Street)
+		}
+		if result.Phone != "" {
+			t.Errorf("Phone: expected skipped embedded struct, got %q", result.Phone)
+		}
+	})
+}
+
+func TestPopulate_SQLNullTypes(t *testing.T) {
+	type Model struct {
+		Name    sql.NullString    `formfield:"name"`
+		Count   sql.NullInt64     `formfield:"count"`
+		Small   sql.NullInt32     `formfield:"small"`
+		Ratio   sql.NullFloat64   `formfield:"ratio"`
+		Active  sql.NullBool      `formfield:"active"`
+		Seen    sql.NullTime      `formfield:"seen"`
+		Generic sql.Null[uint]    `formfield:"generic"`
+		Ptr     *sql.NullString   `formfield:"ptr"`
+		List    []sql.NullFloat64 `formfield:"list"`
+	}
+
+	t.Run("present values", func(t *testing.T) {
+		formData := url.Values{
+			"name":    {"Jane"},
+			"count":   {"42"},
+			"small":   {"-7"},
+			"ratio":   {"0.5"},
+			"active":  {"on"},
+			"seen":    {"2024-01-02T03:04:05Z"},
+			"generic": {"9"},
+			"ptr":     {"pointer"},
+			"list":    {"1.5", ""},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Model
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Name != (sql.NullString{String: "Jane", Valid: true}) {
+			t.Errorf("Name: got %+v", result.Name)
+		}
+		if result.Count != (sql.NullInt64{Int64: 42, Valid: true}) {
+			t.Errorf("Count: got %+v", result.Count)
+		}
+		if result.Small != (sql.NullInt32{Int32: -7, Valid: true}) {
+			t.Errorf("Small: got %+v", result.Small)
+		}
+		if result.Ratio != (sql.NullFloat64{Float64: 0.5, Valid: true}) {
+			t.Errorf("Ratio: got %+v", result.Ratio)
+		}
+		if result.Active != (sql.NullBool{Bool: true, Valid: true}) {
+			t.Errorf("Active: got %+v", result.Active)
+		}
+		if !result.Seen.Valid || !result.Seen.Time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+			t.Errorf("Seen: got %+v", result.Seen)
+		}
+		if result.Generic != (sql.Null[uint]{V: 9, Valid: true}) {
+			t.Errorf("Generic: got %+v", result.Generic)
+		}
+		if result.Ptr == nil || *result.Ptr != (sql.NullString{String: "pointer", Valid: true}) {
+			t.Errorf("Ptr: got %+v", result.Ptr)
+		}
+		expectedList := []sql.NullFloat64{{Float64: 1.5, Valid: true}, {}}
+		if !reflect.DeepEqual(result.List, expectedList) {
+			t.Errorf("List: got %+v, want %+v", result.List, expectedList)
+		}
+	})
+
+	t.Run("absent or empty values", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=&count="))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		result := Model{Name: sql.NullString{String: "old", Valid: true}}
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Name.Valid || result.Count.Valid || result.Active.Valid || result.Generic.Valid {
+			t.Errorf("expected all values to be invalid, got %+v", result)
+		}
+		if result.Ptr != nil {
+			t.Errorf("Ptr: expected nil, got %+v", result.Ptr)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("count=many"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Model
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), "failed to set field Count") {
+			t.Errorf("expected conversion error, got %v", err)
+		}
+	})
+}
+
+// cancelingReader cancels its context once the first chunk has been read.
+type cancelingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+	reads  int
+}
+
+func (c *cancelingReader) Read(p []byte) (int, error) {
+	c.reads++
+	if c.reads == 2 {
+		c.cancel()
+	}
+	if len(p) > 16 {
+		p = p[:16]
+	}
+	return c.r.Read(p)
+}
+
+func TestPopulateContext(t *testing.T) {
+	type Form struct {
+		Name string `formfield:"name"`
+	}
+
+	t.Run("completes with live context", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=Jane"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := PopulateContext(context.Background(), req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Name != "Jane" {
+			t.Errorf("Name: got %q, want 'Jane'", result.Name)
+		}
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=Jane"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		err := PopulateContext(ctx, req, &result)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if result.Name != "" {
+			t.Errorf("expected no binding, got %+v", result)
+		}
+	})
+
+	t.Run("cancelled mid-parse", func(t *testing.T) {
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		w.WriteField("name", "Jane")
+		fw, _ := w.CreateFormFile("upload", "big.bin")
+		fw.Write(bytes.Repeat([]byte("x"), 1<<16))
+		w.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		body := &cancelingReader{r: &b, cancel: cancel}
+		req := httptest.NewRequest("POST", "/", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+
+		var result Form
+		err := PopulateContext(ctx, req, &result)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if body.reads > 3 {
+			t.Errorf("expected parsing to stop right after cancellation, got %d reads", body.reads)
+		}
+	})
+}
+
+func TestPopulateMultipart(t *testing.T) {
+	type Upload struct {
+		Title    string                  `formfield:"title"`
+		Tags     []string                `formfield:"tags"`
+		Meta     Contact                 `formfield:"meta"`
+		Document *multipart.FileHeader   `formfield:"document"`
+		Images   []*multipart.FileHeader `formfield:"images"`
+		Missing  *multipart.FileHeader   `formfield:"missing"`
+	}
+
+	doc := &multipart.FileHeader{Filename: "report.pdf", Size: 1024}
+	img1 := &multipart.FileHeader{Filename: "a.png", Size: 10}
+	img2 := &multipart.FileHeader{Filename: "b.png", Size: 20}
+
+	form := &multipart.Form{
+		Value: map[string][]string{
+			"title":      {"Quarterly report"},
+			"tags":       {"finance", "q3"},
+			"meta.email": {"cfo@example.com"},
+		},
+		File: map[string][]*multipart.FileHeader{
+			"document": {doc},
+			"images":   {img1, img2},
+		},
+	}
+
+	var result Upload
+	if err := PopulateMultipart(form, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Upload{
+		Title:    "Quarterly report",
+		Tags:     []string{"finance", "q3"},
+		Meta:     Contact{Email: "cfo@example.com"},
+		Document: doc,
+		Images:   []*multipart.FileHeader{img1, img2},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+
+	t.Run("conversion errors", func(t *testing.T) {
+		form := &multipart.Form{Value: map[string][]string{"age": {"old"}}}
+
+		var result struct {
+			Age int `formfield:"age"`
+		}
+		err := PopulateMultipart(form, &result)
+		if err == nil || !strings.Contains(err.Error(), "failed to set field Age") {
+			t.Errorf("expected conversion error, got %v", err)
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		var result Upload
+		if err := PopulateMultipart(nil, &result); err == nil {
+			t.Error("expected error for nil form")
+		}
+		if err := PopulateMultipart(form, result); err == nil {
+			t.Error("expected error for non-pointer target")
+		}
+	})
+}
+
+func TestPopulate_FileHeaderFields(t *testing.T) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	w.WriteField("title", "holiday")
+	for _, name := range []string{"one.jpg", "two.jpg"} {
+		fw, _ := w.CreateFormFile("photos", name)
+		fw.Write([]byte("image data"))
+	}
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	var result struct {
+		Title  string                  `formfield:"title"`
+		Cover  *multipart.FileHeader   `formfield:"photos"`
+		Photos []*multipart.FileHeader `formfield:"photos"`
+	}
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Title != "holiday" {
+		t.Errorf("Title: got %q, want 'holiday'", result.Title)
+	}
+	if result.Cover == nil || result.Cover.Filename != "one.jpg" {
+		t.Errorf("Cover: expected first upload, got %+v", result.Cover)
+	}
+	if len(result.Photos) != 2 || result.Photos[1].Filename != "two.jpg" {
+		t.Errorf("Photos: got %+v", result.Photos)
+	}
+}
+
+func TestPopulate_NonStringMapKeys(t *testing.T) {
+	type Form struct {
+		Names  map[int]string      `formfield:"names"`
+		Prices map[int64]float64   `formfield:"prices"`
+		Flags  map[uint8]bool      `formfield:"flags"`
+		Groups map[int16][]string  `formfield:"groups"`
+		Ratios map[float64]float64 `formfield:"ratios"`
+	}
+
+	t.Run("valid keys", func(t *testing.T) {
+		body := "names=1:one&names=2:two&prices[100]=9.99&prices[-5]=0.5&flags=7:on&groups[1]=a&groups[1]=b&ratios=0.5:2"
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := Form{
+			Names:  map[int]string{1: "one", 2: "two"},
+			Prices: map[int64]float64{100: 9.99, -5: 0.5},
+			Flags:  map[uint8]bool{7: true},
+			Groups: map[int16][]string{1: {"a", "b"}},
+			Ratios: map[float64]float64{0.5: 2},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"invalid int key", "names=one:1", `failed to set field Names: invalid map key "one"`},
+		{"invalid bracket key", "prices[abc]=1", `failed to set field Prices: invalid map key "abc"`},
+		{"overflowing key", "flags=300:on", `failed to set field Flags: invalid map key "300"`},
+		{"invalid value", "prices=1:cheap", `failed to set field Prices: invalid value for map key "1"`},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, should start with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPopulateWithOptions_PreserveOnEmpty(t *testing.T) {
+	type Form struct {
+		Name    string   `formfield:"name"`
+		Theme   string   `formfield:"theme"`
+		Limit   int      `formfield:"limit"`
+		Tags    []string `formfield:"tags"`
+		Country *string  `formfield:"country"`
+	}
+
+	defaultCountry := "NZ"
+	defaults := func() Form {
+		return Form{Name: "anonymous", Theme: "light", Limit: 10, Tags: []string{"default"}, Country: &defaultCountry}
+	}
+
+	body := "name=&theme=dark&limit=&tags=&tags=&country="
+
+	t.Run("enabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		opts := DefaultOptions()
+		opts.PreserveOnEmpty = true
+
+		result := defaults()
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := defaults()
+		expected.Theme = "dark"
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=&theme=dark"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		result := defaults()
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Name != "" {
+			t.Errorf("Name: expected empty value to overwrite default, got %q", result.Name)
+		}
+	})
+}
+
+func TestPopulateWithOptions_NestingSeparator(t *testing.T) {
+	type Inner struct {
+		Value string `formfield:"value"`
+	}
+	type Form struct {
+		Contact Contact `formfield:"contact"`
+		Inner   *Inner  `formfield:"inner"`
+		Deep    struct {
+			Inner Inner `formfield:"inner"`
+		} `formfield:"deep"`
+	}
+
+	formData := url.Values{
+		"contact__email":     {"jane@example.com"},
+		"contact.phone":      {"ignored"},
+		"inner__value":       {"pointer"},
+		"deep__inner__value": {"nested"},
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	opts := DefaultOptions()
+	opts.NestingSeparator = "__"
+
+	var result Form
+	if err := PopulateWithOptions(req, &result, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Contact.Email != "jane@example.com" || result.Contact.Phone != "" {
+		t.Errorf("Contact: got %+v", result.Contact)
+	}
+	if result.Inner == nil || result.Inner.Value != "pointer" {
+		t.Errorf("Inner: got %+v", result.Inner)
+	}
+	if result.Deep.Inner.Value != "nested" {
+		t.Errorf("Deep.Inner.Value: got %q, want 'nested'", result.Deep.Inner.Value)
+	}
+}
+
+func TestPopulateValue(t *testing.T) {
+	t.Run("addressable struct value", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=Jane&age=30&contact.email=jane@example.com"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Person
+		if err := PopulateValue(req, reflect.ValueOf(&result).Elem()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := Person{Name: "Jane", Age: 30, Contact: Contact{Email: "jane@example.com"}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("runs Validate", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("password=a&confirm=b"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var form signupForm
+		if err := PopulateValue(req, reflect.ValueOf(&form).Elem()); !errors.Is(err, errPasswordMismatch) {
+			t.Errorf("expected validation error, got %v", err)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		value   reflect.Value
+		wantErr string
+	}{
+		{"non-addressable struct", reflect.ValueOf(Person{}), "value must be settable"},
+		{"pointer value", reflect.ValueOf(&Person{}), "value must be a struct, got ptr"},
+		{"non-struct value", reflect.ValueOf(new(string)).Elem(), "value must be a struct, got string"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader("name=Jane"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			err := PopulateValue(req, tt.value)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPopulateWithOptions_UseLastValue(t *testing.T) {
+	type Form struct {
+		Age     int      `formfield:"age"`
+		Name    *string  `formfield:"name"`
+		Active  bool     `formfield:"active"`
+		Contact Contact  `formfield:"contact"`
+		Tags    []string `formfield:"tags"`
+		Scores  [2]int   `formfield:"scores"`
+	}
+
+	body := "age=30&age=40&name=first&name=last&active=false&active=true" +
+		"&contact=" + url.QueryEscape(`{"phone":"1"}`) + "&contact=" + url.QueryEscape(`{"phone":"2"}`) +
+		"&tags=a&tags=b&scores=1&scores=2"
+
+	tests := []struct {
+		name       string
+		useLast    bool
+		wantAge    int
+		wantName   string
+		wantActive bool
+		wantPhone  string
+	}{
+		{name: "first value by default", useLast: false, wantAge: 30, wantName: "first", wantActive: false, wantPhone: "1"},
+		{name: "last value when enabled", useLast: true, wantAge: 40, wantName: "last", wantActive: true, wantPhone: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts := DefaultOptions()
+			opts.UseLastValue = tt.useLast
+
+			var result Form
+			if err := PopulateWithOptions(req, &result, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Age != tt.wantAge {
+				t.Errorf("Age: got %d, want %d", result.Age, tt.wantAge)
+			}
+			if result.Name == nil || *result.Name != tt.wantName {
+				t.Errorf("Name: got %v, want %q", result.Name, tt.wantName)
+			}
+			if result.Active != tt.wantActive {
+				t.Errorf("Active: got %v, want %v", result.Active, tt.wantActive)
+			}
+			if result.Contact.Phone != tt.wantPhone {
+				t.Errorf("Contact.Phone: got %q, want %q", result.Contact.Phone, tt.wantPhone)
+			}
+			if !reflect.DeepEqual(result.Tags, []string{"a", "b"}) {
+				t.Errorf("Tags: expected every value, got %v", result.Tags)
+			}
+			if result.Scores != [2]int{1, 2} {
+				t.Errorf("Scores: expected every value, got %v", result.Scores)
+			}
+		})
+	}
+}
+
+// rangeFilter reads a minimum and a maximum from two values posted under
+// the same key.
+type rangeFilter struct {
+	Min int
+	Max int
+}
+
+func (r *rangeFilter) UnmarshalForm(values []string) error {
+	if len(values) != 2 {
+		return fmt.Errorf("expected 2 values, got %d", len(values))
+	}
+
+	lo, err := strconv.Atoi(values[0])
+	if err != nil {
+		return err
+	}
+	hi, err := strconv.Atoi(values[1])
+	if err != nil {
+		return err
+	}
+
+	r.Min, r.Max = lo, hi
+	return nil
+}
+
+// csvSet is a named map type that binds from comma-separated values.
+type csvSet map[string]bool
+
+func (s *csvSet) UnmarshalForm(values []string) error {
+	*s = csvSet{}
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			(*s)[item] = true
+		}
+	}
+	return nil
+}
+
+func TestPopulate_FormUnmarshaler(t *testing.T) {
+	type Form struct {
+		Price    rangeFilter  `formfield:"price"`
+		Year     *rangeFilter `formfield:"year"`
+		Features csvSet       `formfield:"features"`
+	}
+
+	t.Run("multiple values", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("price=10&price=50&year=1990&year=2000&features=a,b&features=c"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Price != (rangeFilter{Min: 10, Max: 50}) {
+			t.Errorf("Price: got %+v", result.Price)
+		}
+		if result.Year == nil || *result.Year != (rangeFilter{Min: 1990, Max: 2000}) {
+			t.Errorf("Year: got %+v", result.Year)
+		}
+		if !reflect.DeepEqual(result.Features, csvSet{"a": true, "b": true, "c": true}) {
+			t.Errorf("Features: got %v", result.Features)
+		}
+	})
+
+	t.Run("absent values", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(""))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Year != nil || result.Price != (rangeFilter{}) {
+			t.Errorf("expected zero values, got %+v", result)
+		}
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("price=10"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || err.Error() != "failed to set field Price: expected 2 values, got 1" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+// cents is an sql.Scanner that reads an amount such as "12.34" as 1234.
+type cents struct{ Amount int64 }
+
+func (c *cents) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported source %T", src)
+	}
+	units, frac, _ := strings.Cut(s, ".")
+	n, err := strconv.ParseInt(units+(frac + "00")[:2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", s)
+	}
+	c.Amount = n
+	return nil
+}
+
+func (c cents) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", c.Amount/100, c.Amount%100), nil
+}
+
+func TestPopulate_Scanner(t *testing.T) {
+	type Form struct {
+		Price    cents          `formfield:"price"`
+		Discount *cents         `formfield:"discount"`
+		Note     sql.NullString `formfield:"note"`
+	}
+
+	t.Run("scan values", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?price=12.34&discount=1.5", nil)
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Price.Amount != 1234 {
+			t.Errorf("Price: got %+v", result.Price)
+		}
+		if result.Discount == nil || result.Discount.Amount != 150 {
+			t.Errorf("Discount: got %+v", result.Discount)
+		}
+		if result.Note.Valid {
+			t.Errorf("Note: got %+v, want invalid", result.Note)
+		}
+	})
+
+	t.Run("absent values", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Price != (cents{}) || result.Discount != nil {
+			t.Errorf("expected zero values, got %+v", result)
+		}
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?price=abc", nil)
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || err.Error() != `failed to set field Price: invalid amount "abc"` {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("encode round trip", func(t *testing.T) {
+		values, err := Encode(Form{Price: cents{Amount: 1234}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := values.Get("price"); got != "12.34" {
+			t.Errorf("price = %q, want %q", got, "12.34")
+		}
+	})
+}
+
+func TestPopulate_BlankStructValues(t *testing.T) {
+	type Form struct {
+		Contact  Contact    `formfield:"contact"`
+		Optional *Contact   `formfield:"optional"`
+		List     []Contact  `formfield:"list"`
+		Ptrs     []*Contact `formfield:"ptrs"`
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "empty values", body: "contact=&optional=&list=&ptrs="},
+		{name: "null values", body: "contact=null&optional=null&list=null&ptrs=+null+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Contact != (Contact{}) {
+				t.Errorf("Contact: expected zero value, got %+v", result.Contact)
+			}
+			if result.Optional != nil {
+				t.Errorf("Optional: expected nil, got %+v", result.Optional)
+			}
+			if !reflect.DeepEqual(result.List, []Contact{{}}) {
+				t.Errorf("List: expected one zero element, got %+v", result.List)
+			}
+			if len(result.Ptrs) != 1 || result.Ptrs[0] == nil || *result.Ptrs[0] != (Contact{}) {
+				t.Errorf("Ptrs: expected one zero element, got %+v", result.Ptrs)
+			}
+		})
+	}
+
+	t.Run("null with dot notation", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("optional=null&optional.phone=555"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Optional == nil || result.Optional.Phone != "555" {
+			t.Errorf("Optional: expected dot-notation value, got %+v", result.Optional)
+		}
+	})
+}
+
+func TestPopulateWithOptions_StripNumberSeparators(t *testing.T) {
+	type Form struct {
+		Count  int       `formfield:"count"`
+		Total  uint64    `formfield:"total"`
+		Amount float64   `formfield:"amount"`
+		Values []int     `formfield:"values"`
+		Ptr    *int32    `formfield:"ptr"`
+		Names  []string  `formfield:"names"`
+		Floats []float32 `formfield:"floats"`
+	}
+
+	formData := url.Values{
+		"count":  {"1,000"},
+		"total":  {"1 000 000"},
+		"amount": {"12,345.67"},
+		"values": {"1,000", "2 000"},
+		"ptr":    {"-3 500"},
+		"names":  {"Doe, Jane"},
+		"floats": {"1,5"},
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		opts := DefaultOptions()
+		opts.StripNumberSeparators = true
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ptr := int32(-3500)
+		expected := Form{
+			Count:  1000,
+			Total:  1000000,
+			Amount: 12345.67,
+			Values: []int{1000, 2000},
+			Ptr:    &ptr,
+			Names:  []string{"Doe, Jane"},
+			Floats: []float32{15},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("count=1,000"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err == nil {
+			t.Errorf("expected error for separated number, got %+v", result)
+		}
+	})
+}
+
+func TestPopulate_MapOfStructs(t *testing.T) {
+	type Form struct {
+		Addresses map[string]Address `formfield:"addresses"`
+		Offices   map[int]*Address   `formfield:"offices"`
+		Labels    map[string]string  `formfield:"labels"`
+		Unused    map[string]Address `formfield:"unused"`
+	}
+
+	formData := url.Values{
+		"addresses[home].street": {"1 Main St"},
+		"addresses[home].city":   {"NYC"},
+		"addresses[home].zip":    {"10001"},
+		"addresses[work].street": {"200 Market St"},
+		"addresses[work].city":   {"Boston"},
+		"offices[1].city":        {"Cairo"},
+		"offices[2].city":        {"Giza"},
+		"offices[2].zip":         {"12511"},
+		"labels[env]":            {"prod"},
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Form
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Form{
+		Addresses: map[string]Address{
+			"home": {Street: "1 Main St", City: "NYC", ZipCode: "10001"},
+			"work": {Street: "200 Market St", City: "Boston"},
+		},
+		Offices: map[int]*Address{
+			1: {City: "Cairo"},
+			2: {City: "Giza", ZipCode: "12511"},
+		},
+		Labels: map[string]string{"env": "prod"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+
+	t.Run("invalid key", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("offices[abc].city=Cairo"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), `invalid map key "abc"`) {
+			t.Errorf("expected invalid map key error, got %v", err)
+		}
+	})
+}
+
+func TestPopulateWithOptions_DisallowUnknownFields(t *testing.T) {
+	type Form struct {
+		Name    string            `formfield:"name"`
+		Tags    []string          `formfield:"tags"`
+		Labels  map[string]string `formfield:"labels"`
+		Contact Contact           `formfield:"contact"`
+	}
+
+	tests := []struct {
+		name    string
+		form    url.Values
+		ignore  []string
+		wantErr string
+	}{
+		{
+			name: "all keys known",
+			form: url.Values{
+				"name":          {"John"},
+				"tags":          {"a", "b"},
+				"labels[env]":   {"prod"},
+				"contact.email": {"john@example.com"},
+			},
+		},
+		{
+			name:    "extra key",
+			form:    url.Values{"name": {"John"}, "nickname": {"Johnny"}},
+			wantErr: `unknown form field "nickname"`,
+		},
+		{
+			name:    "misspelled nested key",
+			form:    url.Values{"contact.mail": {"john@example.com"}},
+			wantErr: `unknown form field "contact.mail"`,
+		},
+		{
+			name:   "ignored key",
+			form:   url.Values{"name": {"John"}, "csrf_token": {"abc"}},
+			ignore: []string{"csrf_token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts := DefaultOptions()
+			opts.DisallowUnknownFields = true
+			opts.IgnoreUnknownFields = tt.ignore
+
+			var result Form
+			err := PopulateWithOptions(req, &result, opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=John&nickname=Johnny"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestPopulate_InterfaceFields(t *testing.T) {
+	type Form struct {
+		Meta  any   `formfield:"meta"`
+		Ptr   *any  `formfield:"ptr"`
+		Items []any `formfield:"items"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected any
+		wantErr  bool
+	}{
+		{
+			name:     "single value",
+			formData: url.Values{"meta": {"hello"}},
+			expected: "hello",
+		},
+		{
+			name:     "multiple values",
+			formData: url.Values{"meta": {"a", "b"}},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "JSON object",
+			formData: url.Values{"meta": {`{"source":"ads","count":2}`}},
+			expected: map[string]any{"source": "ads", "count": float64(2)},
+		},
+		{
+			name:     "JSON array",
+			formData: url.Values{"meta": {`[1,"two"]`}},
+			expected: []any{float64(1), "two"},
+		},
+		{
+			name:     "invalid JSON",
+			formData: url.Values{"meta": {`{"source":}`}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Meta, tt.expected) {
+				t.Errorf("got %#v, want %#v", result.Meta, tt.expected)
+			}
+		})
+	}
+
+	t.Run("pointer and slice", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("ptr=x&items=1&items=2"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Ptr == nil || *result.Ptr != "x" {
+			t.Errorf("expected Ptr to be x, got %v", result.Ptr)
+		}
+		if !reflect.DeepEqual(result.Items, []any{"1", "2"}) {
+			t.Errorf("got %#v, want [1 2]", result.Items)
+		}
+	})
+
+	t.Run("non-empty interface", func(t *testing.T) {
+		type Form struct {
+			Value fmt.Stringer `formfield:"value"`
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader("value=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err == nil || !strings.Contains(err.Error(), "unsupported field type") {
+			t.Errorf("expected unsupported field type error, got %v", err)
+		}
+	})
+}
+
+func TestPopulateWithOptions_FieldHook(t *testing.T) {
+	type Form struct {
+		Email string   `formfield:"email"`
+		Phone *string  `formfield:"phone"`
+		Tags  []string `formfield:"tags"`
+		Age   int      `formfield:"age"`
+	}
+
+	t.Run("normalizes values", func(t *testing.T) {
+		formData := url.Values{
+			"email": {"John@Example.COM"},
+			"phone": {"(555) 123-4567"},
+			"tags":  {"Go", "WEB"},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var seen []string
+		opts := DefaultOptions()
+		opts.FieldHook = func(field reflect.StructField, values []string) ([]string, error) {
+			seen = append(seen, field.Name)
+			out := make([]string, len(values))
+			for i, v := range values {
+				switch field.Name {
+				case "Phone":
+					out[i] = strings.Map(func(r rune) rune {
+						if r < '0' || r > '9' {
+							return -1
+						}
+						return r
+					}, v)
+				default:
+					out[i] = strings.ToLower(v)
+				}
+			}
+			return out, nil
+		}
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Email != "john@example.com" {
+			t.Errorf("expected lowercased email, got %q", result.Email)
+		}
+		if result.Phone == nil || *result.Phone != "5551234567" {
+			t.Errorf("expected stripped phone, got %v", result.Phone)
+		}
+		if !reflect.DeepEqual(result.Tags, []string{"go", "web"}) {
+			t.Errorf("expected lowercased tags, got %v", result.Tags)
+		}
+		if want := []string{"Email", "Phone", "Tags"}; !reflect.DeepEqual(seen, want) {
+			t.Errorf("hook called for %v, want %v", seen, want)
+		}
+	})
+
+	t.Run("rejects value", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("email=john@example.com&age=-1"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		errNegative := errors.New("must not be negative")
+		opts := DefaultOptions()
+		opts.FieldHook = func(field reflect.StructField, values []string) ([]string, error) {
+			if field.Name == "Age" && strings.HasPrefix(values[0], "-") {
+				return nil, errNegative
+			}
+			return values, nil
+		}
+
+		var result Form
+		err := PopulateWithOptions(req, &result, opts)
+		if !errors.Is(err, errNegative) {
+			t.Fatalf("expected hook error, got %v", err)
+		}
+
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "Age" {
+			t.Errorf("expected BindError for Age, got %v", err)
+		}
+	})
+}
+
+func TestPopulate_ArrayOfStructs(t *testing.T) {
+	type Form struct {
+		Stops     [2]Address  `formfield:"stops"`
+		Backups   [1]*Address `formfield:"backups"`
+		Untouched [2]Address  `formfield:"untouched"`
+	}
+
+	t.Run("fills elements", func(t *testing.T) {
+		formData := url.Values{
+			"stops[0].street": {"1 Main St"},
+			"stops[0].city":   {"NYC"},
+			"stops[1].city":   {"Boston"},
+			"stops[1].zip":    {"02108"},
+			"backups[0].city": {"Cairo"},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := Form{
+			Stops: [2]Address{
+				{Street: "1 Main St", City: "NYC"},
+				{City: "Boston", ZipCode: "02108"},
+			},
+			Backups: [1]*Address{{City: "Cairo"}},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("ignores overflow", func(t *testing.T) {
+		formData := url.Values{
+			"stops[1].city": {"Boston"},
+			"stops[2].city": {"Denver"},
+			"stops[9].city": {"Austin"},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := [2]Address{{}, {City: "Boston"}}
+		if result.Stops != expected {
+			t.Errorf("got %+v, want %+v", result.Stops, expected)
+		}
+	})
+
+	t.Run("invalid index", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("stops[first].city=NYC"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), `invalid index "first"`) {
+			t.Errorf("expected invalid index error, got %v", err)
+		}
+	})
+}
+
+func TestPopulate_SliceOfStructs(t *testing.T) {
+	type Item struct {
+		Name string `formfield:"name"`
+		Qty  int    `formfield:"qty"`
+	}
+	type Form struct {
+		Items []*Item   `formfield:"items"`
+		Stops []Address `formfield:"stops"`
+	}
+
+	t.Run("pointer elements", func(t *testing.T) {
+		formData := url.Values{
+			"items[0].name": {"Pen"},
+			"items[0].qty":  {"2"},
+			"items[1].name": {"Ink"},
+			"items[1].qty":  {"5"},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []*Item{{Name: "Pen", Qty: 2}, {Name: "Ink", Qty: 5}}
+		if !reflect.DeepEqual(result.Items, expected) {
+			t.Errorf("got %v, want %v", result.Items, expected)
+		}
+	})
+
+	t.Run("value elements in index order with gaps closed", func(t *testing.T) {
+		formData := url.Values{
+			"stops[10].city": {"Denver"},
+			"stops[2].city":  {"Boston"},
+			"stops[0].city":  {"NYC"},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		result := Form{Stops: []Address{{City: "Old"}}}
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []Address{{City: "NYC"}, {City: "Boston"}, {City: "Denver"}}
+		if !reflect.DeepEqual(result.Stops, expected) {
+			t.Errorf("got %+v, want %+v", result.Stops, expected)
+		}
+	})
+
+	t.Run("invalid element value", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?items[0].qty=many", nil)
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), "failed to set field Qty") {
+			t.Errorf("got %v, want an error for Qty", err)
+		}
+	})
+
+	t.Run("invalid index", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?items[-1].name=Pen", nil)
+
+		var result Form
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), `invalid index "-1"`) {
+			t.Errorf("expected invalid index error, got %v", err)
+		}
+	})
+}
+
+// explodingValue is a FormUnmarshaler that panics, as buggy custom types can.
+type explodingValue struct{ items []string }
+
+func (e *explodingValue) UnmarshalForm(values []string) error {
+	_ = e.items[len(values)]
+	return nil
+}
+
+func TestPopulateWithOptions_RecoverPanics(t *testing.T) {
+	type Form struct {
+		Name  string         `formfield:"name"`
+		Value explodingValue `formfield:"value"`
+		Inner struct {
+			Code string `formfield:"code"`
+		} `formfield:"inner"`
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		hook      func(reflect.StructField, []string) ([]string, error)
+		wantField string
+	}{
+		{
+			name:      "FormUnmarshaler panic",
+			body:      "name=John&value=x",
+			wantField: "Value",
+		},
+		{
+			name: "FieldHook panic",
+			body: "name=John&inner.code=abc",
+			hook: func(field reflect.StructField, values []string) ([]string, error) {
+				if field.Name == "Code" {
+					var m map[string]string
+					m["code"] = values[0]
+				}
+				return values, nil
+			},
+			wantField: "Code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts := DefaultOptions()
+			opts.RecoverPanics = true
+			opts.FieldHook = tt.hook
+
+			var result Form
+			err := PopulateWithOptions(req, &result, opts)
+			want := "panic while setting field " + tt.wantField + ": "
+			if err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("error = %v, should start with %q", err, want)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("value=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic without RecoverPanics")
+			}
+		}()
+
+		var result Form
+		_ = Populate(req, &result)
+	})
+}
+
+func TestPopulate_CharFields(t *testing.T) {
+	type Form struct {
+		Grade  rune  `formfield:"grade" rune:"char"`
+		Symbol *rune `formfield:"symbol" rune:"char"`
+		Sep    byte  `formfield:"sep" rune:"char"`
+		Code   rune  `formfield:"code"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		check    func(t *testing.T, result Form)
+		wantErr  string
+	}{
+		{
+			name:     "ASCII character",
+			formData: url.Values{"grade": {"A"}, "sep": {";"}},
+			check: func(t *testing.T, result Form) {
+				if result.Grade != 'A' || result.Sep != ';' {
+					t.Errorf("got Grade=%q Sep=%q", result.Grade, result.Sep)
+				}
+			},
+		},
+		{
+			name:     "multibyte character",
+			formData: url.Values{"grade": {"é"}, "symbol": {"€"}},
+			check: func(t *testing.T, result Form) {
+				if result.Grade != 'é' {
+					t.Errorf("got Grade=%q, want 'é'", result.Grade)
+				}
+				if result.Symbol == nil || *result.Symbol != '€' {
+					t.Errorf("got Symbol=%v, want '€'", result.Symbol)
+				}
+			},
+		},
+		{
+			name:     "byte takes first byte",
+			formData: url.Values{"sep": {"|x"}},
+			check: func(t *testing.T, result Form) {
+				if result.Sep != '|' {
+					t.Errorf("got Sep=%q, want '|'", result.Sep)
+				}
+			},
+		},
+		{
+			name:     "untagged rune stays numeric",
+			formData: url.Values{"code": {"65"}},
+			check: func(t *testing.T, result Form) {
+				if result.Code != 65 {
+					t.Errorf("got Code=%d, want 65", result.Code)
+				}
+			},
+		},
+		{
+			name:     "untagged rune rejects characters",
+			formData: url.Values{"code": {"A"}},
+			wantErr:  "failed to set field Code",
+		},
+		{
+			name:     "multiple characters",
+			formData: url.Values{"grade": {"AB"}},
+			wantErr:  `expected a single character, got "AB"`,
+		},
+		{
+			name:     "empty value",
+			formData: url.Values{"grade": {""}},
+			wantErr:  `expected a single character, got ""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, result)
+		})
+	}
+}
+
+func TestPopulate_IndexedArrayValues(t *testing.T) {
+	type Form struct {
+		Scores [5]int    `formfield:"scores"`
+		Names  [2]string `formfield:"names"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Form
+		wantErr  string
+	}{
+		{
+			name:     "indexed only",
+			formData: url.Values{"scores[4]": {"99"}, "scores[1]": {"50"}},
+			expected: Form{Scores: [5]int{0, 50, 0, 0, 99}},
+		},
+		{
+			name:     "mixed indexed and sequential",
+			formData: url.Values{"scores": {"95", "87"}, "scores[4]": {"99"}},
+			expected: Form{Scores: [5]int{95, 87, 0, 0, 99}},
+		},
+		{
+			name:     "indexed overrides sequential",
+			formData: url.Values{"names": {"a", "b"}, "names[0]": {"z"}},
+			expected: Form{Names: [2]string{"z", "b"}},
+		},
+		{
+			name:     "out of bounds ignored",
+			formData: url.Values{"names[1]": {"b"}, "names[2]": {"c"}, "names[10]": {"d"}},
+			expected: Form{Names: [2]string{"", "b"}},
+		},
+		{
+			name:     "non-index brackets ignored",
+			formData: url.Values{"scores[x]": {"1"}, "scores[-1]": {"2"}, "scores[2]": {"3"}},
+			expected: Form{Scores: [5]int{0, 0, 3, 0, 0}},
+		},
+		{
+			name:     "empty brackets ignored",
+			formData: url.Values{"scores[]": {"1", "2"}, "names": {"a"}},
+			expected: Form{Names: [2]string{"a", ""}},
+		},
+		{
+			name:     "invalid element",
+			formData: url.Values{"scores[3]": {"abc"}},
+			wantErr:  "failed to set field Scores: scores[3]: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPopulateWithOptions_DecompressBody(t *testing.T) {
+	type Form struct {
+		Name string `formfield:"name"`
+		Age  int    `formfield:"age"`
+	}
+
+	compress := func(t *testing.T, encoding, body string) *bytes.Buffer {
+		t.Helper()
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w = fw
+		}
+		if _, err := io.WriteString(w, body); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", compress(t, encoding, "name=John&age=30"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Content-Encoding", encoding)
+			body := req.Body
+
+			opts := DefaultOptions()
+			opts.DecompressBody = true
+
+			var result Form
+			if err := PopulateWithOptions(req, &result, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != (Form{Name: "John", Age: 30}) {
+				t.Errorf("got %+v", result)
+			}
+			if req.Body != body {
+				t.Error("expected the original body to be restored")
+			}
+		})
+	}
+
+	t.Run("bound twice", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", compress(t, "gzip", "name=John&age=30"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Content-Encoding", "gzip")
+
+		opts := DefaultOptions()
+		opts.DecompressBody = true
+
+		for i := range 2 {
+			var result Form
+			if err := PopulateWithOptions(req, &result, opts); err != nil {
+				t.Fatalf("bind %d: unexpected error: %v", i+1, err)
+			}
+			if result != (Form{Name: "John", Age: 30}) {
+				t.Errorf("bind %d: got %+v", i+1, result)
+			}
+		}
+	})
+
+	t.Run("corrupt gzip", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Content-Encoding", "gzip")
+
+		opts := DefaultOptions()
+		opts.DecompressBody = true
+
+		var result Form
+		err := PopulateWithOptions(req, &result, opts)
+		if err == nil || !strings.Contains(err.Error(), "failed to decompress body") {
+			t.Errorf("expected decompression error, got %v", err)
+		}
+	})
+
+	t.Run("uncompressed body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		opts := DefaultOptions()
+		opts.DecompressBody = true
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Name != "John" {
+			t.Errorf("got %+v", result)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", compress(t, "gzip", "name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Content-Encoding", "gzip")
+
+		var result Form
+		_ = Populate(req, &result)
+		if result.Name == "John" {
+			t.Error("expected the compressed body to be left alone")
+		}
+	})
+}
+
+func TestPopulate_MapFromJSON(t *testing.T) {
+	type Form struct {
+		Meta   map[string]any     `formfield:"meta"`
+		Counts map[string]int     `formfield:"counts"`
+		Ptr    *map[string]string `formfield:"ptr"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Form
+		wantErr  bool
+	}{
+		{
+			name:     "JSON object into map of any",
+			formData: url.Values{"meta": {`{"source":"ads","tags":["a","b"],"nested":{"x":1}}`}},
+			expected: Form{Meta: map[string]any{
+				"source": "ads",
+				"tags":   []any{"a", "b"},
+				"nested": map[string]any{"x": float64(1)},
+			}},
+		},
+		{
+			name:     "JSON object into typed map",
+			formData: url.Values{"counts": {` {"a":1,"b":2} `}},
+			expected: Form{Counts: map[string]int{"a": 1, "b": 2}},
+		},
+		{
+			name:     "JSON object into pointer to map",
+			formData: url.Values{"ptr": {`{"k":"v"}`}},
+			expected: Form{Ptr: &map[string]string{"k": "v"}},
+		},
+		{
+			name:     "colon form still works",
+			formData: url.Values{"meta": {"source:ads"}, "counts": {"a:1", "b:2"}},
+			expected: Form{
+				Meta:   map[string]any{"source": "ads"},
+				Counts: map[string]int{"a": 1, "b": 2},
+			},
+		},
+		{
+			name:     "invalid JSON",
+			formData: url.Values{"counts": {`{"a":"one"}`}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPopulateReport(t *testing.T) {
+	type Form struct {
+		Name     string            `formfield:"name"`
+		Age      int               `formfield:"age"`
+		Nickname *string           `formfield:"nickname"`
+		Contact  Contact           `formfield:"contact"`
+		Billing  *Address          `formfield:"billing"`
+		Labels   map[string]string `formfield:"labels"`
+		Skipped  string            `formfield:"-"`
+	}
+
+	formData := url.Values{
+		"name":          {"John"},
+		"contact.email": {"john@example.com"},
+		"labels[env]":   {"prod"},
+		"extra":         {"ignored"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Form
+	report, err := PopulateReport(req, &result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Name != "John" || result.Contact.Email != "john@example.com" || result.Labels["env"] != "prod" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	expected := Report{
+		Consumed: []string{"contact.email", "labels[env]", "name"},
+		Bound:    []string{"name", "contact.email", "labels"},
+		Unbound:  []string{"age", "nickname", "contact.phone", "billing"},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("got %+v, want %+v", report, expected)
+	}
+
+	t.Run("optional section submitted", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("billing.city=NYC&nickname=JD"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		report, err := PopulateReport(req, &result)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !slices.Contains(report.Bound, "billing.city") || !slices.Contains(report.Bound, "nickname") {
+			t.Errorf("expected billing.city and nickname to be bound, got %v", report.Bound)
+		}
+		if !slices.Contains(report.Unbound, "billing.street") {
+			t.Errorf("expected billing.street to be unbound, got %v", report.Unbound)
+		}
+	})
+
+	t.Run("error keeps partial report", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=John&age=abc"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		report, err := PopulateReport(req, &result)
+		if err == nil {
+			t.Fatal("expected error for invalid age")
+		}
+		if !reflect.DeepEqual(report.Bound, []string{"name"}) {
+			t.Errorf("got Bound %v, want [name]", report.Bound)
+		}
+	})
+}
+
+func TestPopulate_TimeFields(t *testing.T) {
+	type Form struct {
+		Created  time.Time  `formfield:"created"`
+		Birthday time.Time  `formfield:"birthday" timeformat:"2006-01-02"`
+		Seen     time.Time  `formfield:"seen" timeformat:"unix"`
+		Clicked  time.Time  `formfield:"clicked" timeformat:"unixmilli"`
+		Expires  *time.Time `formfield:"expires" timeformat:"unix"`
+		Due      time.Time  `formfield:"due" timeformat:"2006-01-02|2006/01/02|02-01-2006"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		field    func(Form) time.Time
+		expected time.Time
+		wantErr  string
+	}{
+		{
+			name:     "RFC 3339 by default",
+			formData: url.Values{"created": {"2024-03-15T09:30:00Z"}},
+			field:    func(f Form) time.Time { return f.Created },
+			expected: time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "layout",
+			formData: url.Values{"birthday": {"1990-05-01"}},
+			field:    func(f Form) time.Time { return f.Birthday },
+			expected: time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "unix seconds",
+			formData: url.Values{"seen": {"1710495000"}},
+			field:    func(f Form) time.Time { return f.Seen },
+			expected: time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "unix milliseconds",
+			formData: url.Values{"clicked": {"1710495000123"}},
+			field:    func(f Form) time.Time { return f.Clicked },
+			expected: time.Date(2024, 3, 15, 9, 30, 0, 123e6, time.UTC),
+		},
+		{
+			name:     "pointer with unix seconds",
+			formData: url.Values{"expires": {"0"}},
+			field:    func(f Form) time.Time { return *f.Expires },
+			expected: time.Unix(0, 0),
+		},
+		{
+			name:     "invalid unix value",
+			formData: url.Values{"seen": {"yesterday"}},
+			wantErr:  "failed to set field Seen",
+		},
+		{
+			name:     "invalid layout value",
+			formData: url.Values{"birthday": {"05/01/1990"}},
+			wantErr:  "failed to set field Birthday",
+		},
+		{
+			name:     "first fallback layout",
+			formData: url.Values{"due": {"2024-03-15"}},
+			field:    func(f Form) time.Time { return f.Due },
+			expected: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "second fallback layout",
+			formData: url.Values{"due": {"2024/03/15"}},
+			field:    func(f Form) time.Time { return f.Due },
+			expected: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "third fallback layout",
+			formData: url.Values{"due": {"15-03-2024"}},
+			field:    func(f Form) time.Time { return f.Due },
+			expected: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "value matching no fallback layout",
+			formData: url.Values{"due": {"March 15"}},
+			wantErr:  `does not match any of the layouts ["2006-01-02" "2006/01/02" "02-01-2006"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tt.field(result); !got.Equal(tt.expected) {
+				t.Errorf("got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPopulateWithOptions_CheckboxSemantics(t *testing.T) {
+	type Prefs struct {
+		Alerts bool `formfield:"alerts"`
+	}
+	type Form struct {
+		Name       string `formfield:"name"`
+		Newsletter bool   `formfield:"newsletter"`
+		Terms      bool   `formfield:"terms"`
+		Beta       *bool  `formfield:"beta"`
+		Prefs      Prefs  `formfield:"prefs"`
+	}
+
+	beta := true
+	defaults := func() Form {
+		return Form{Name: "default", Newsletter: true, Terms: true, Beta: &beta, Prefs: Prefs{Alerts: true}}
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		opts     func(*Options)
+		expected Form
+	}{
+		{
+			name: "absent bools become false",
+			body: "terms=on",
+			opts: func(o *Options) { o.CheckboxSemantics = true },
+			expected: Form{
+				Name: "default", Newsletter: false, Terms: true, Beta: &beta, Prefs: Prefs{Alerts: false},
+			},
+		},
+		{
+			name: "overrides PreserveOnEmpty",
+			body: "name=&newsletter=&terms=on",
+			opts: func(o *Options) {
+				o.CheckboxSemantics = true
+				o.PreserveOnEmpty = true
+			},
+			expected: Form{
+				Name: "default", Newsletter: false, Terms: true, Beta: &beta, Prefs: Prefs{Alerts: false},
+			},
+		},
+		{
+			name:     "disabled keeps defaults",
+			body:     "terms=on",
+			opts:     func(o *Options) {},
+			expected: defaults(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts := DefaultOptions()
+			tt.opts(&opts)
+
+			result := defaults()
+			if err := PopulateWithOptions(req, &result, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPopulateWithOptions_PostFormOnly(t *testing.T) {
+	type Form struct {
+		Name string `formfield:"name"`
+		Role string `formfield:"role"`
+	}
+
+	opts := DefaultOptions()
+	opts.PostFormOnly = true
+
+	t.Run("urlencoded", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?role=admin&name=Mallory", strings.NewReader("name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != (Form{Name: "John"}) {
+			t.Errorf("got %+v, want only body values", result)
+		}
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		if err := writer.WriteField("name", "John"); err != nil {
+			t.Fatal(err)
+		}
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/?role=admin", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != (Form{Name: "John"}) {
+			t.Errorf("got %+v, want only body values", result)
+		}
+	})
+
+	t.Run("disabled merges query", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?role=admin", strings.NewReader("name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != (Form{Name: "John", Role: "admin"}) {
+			t.Errorf("got %+v, want merged values", result)
+		}
+	})
+}
+
+func TestPopulate_FileUploadFields(t *testing.T) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	files := []struct {
+		name        string
+		contentType string
+		data        string
+	}{
+		{"one.jpg", "image/jpeg", "jpeg data"},
+		{"two.png", "image/png", "png"},
+		{"notes.txt", "text/plain", "hello, world"},
+	}
+	for _, f := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="attachments"; filename=%q`, f.name))
+		header.Set("Content-Type", f.contentType)
+		fw, err := w.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(f.data))
+	}
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	var result struct {
+		First       FileUpload   `formfield:"attachments"`
+		Attachments []FileUpload `formfield:"attachments"`
+		Missing     []FileUpload `formfield:"missing"`
+	}
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Attachments) != len(files) {
+		t.Fatalf("got %d attachments, want %d", len(result.Attachments), len(files))
+	}
+	for i, f := range files {
+		got := result.Attachments[i]
+		if got.Filename != f.name || got.Size != int64(len(f.data)) || got.ContentType != f.contentType {
+			t.Errorf("attachment %d: got %+v, want %s (%d bytes, %s)", i, got, f.name, len(f.data), f.contentType)
+		}
+		if got.Header == nil || got.Header.Filename != f.name {
+			t.Errorf("attachment %d: expected the file header to be kept", i)
+		}
+	}
+
+	if result.First.Filename != "one.jpg" {
+		t.Errorf("First: expected first upload, got %+v", result.First)
+	}
+	if result.Missing != nil {
+		t.Errorf("Missing: expected nil, got %+v", result.Missing)
+	}
+}
+
+type (
+	tagList     []string
+	headerMap   map[string]string
+	level       int
+	levelName   string
+	levelList   []level
+	levelLookup map[levelName]level
+	groupMap    map[levelName]tagList
+	scoreArray  [3]level
+	metadata    any
+)
+
+func TestPopulate_NamedCollectionTypes(t *testing.T) {
+	type Form struct {
+		Tags    tagList     `formfield:"tags"`
+		Headers headerMap   `formfield:"headers"`
+		Levels  levelList   `formfield:"levels"`
+		Lookup  levelLookup `formfield:"lookup"`
+		Groups  groupMap    `formfield:"groups"`
+		Scores  scoreArray  `formfield:"scores"`
+		PtrTags *tagList    `formfield:"ptrtags"`
+		JSONMap headerMap   `formfield:"jsonmap"`
+		Meta    metadata    `formfield:"meta"`
+		Named   []levelName `formfield:"named"`
+	}
+
+	body := strings.Join([]string{
+		"tags=go", "tags=web",
+		"headers=Accept:text/html", "headers[X-Trace]=abc",
+		"levels=1", "levels=2",
+		"lookup=low:1", "lookup[high]=3",
+		"groups[a]=x", "groups[a]=y", "groups=b:z",
+		"scores=4", "scores[2]=6",
+		"ptrtags=one",
+		`jsonmap={"k":"v"}`,
+		"meta=hi",
+		"named=debug", "named=info",
+	}, "&")
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Form
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ptrTags := tagList{"one"}
+	expected := Form{
+		Tags:    tagList{"go", "web"},
+		Headers: headerMap{"Accept": "text/html", "X-Trace": "abc"},
+		Levels:  levelList{1, 2},
+		Lookup:  levelLookup{"low": 1, "high": 3},
+		Groups:  groupMap{"a": {"x", "y"}, "b": {"z"}},
+		Scores:  scoreArray{4, 0, 6},
+		PtrTags: &ptrTags,
+		JSONMap: headerMap{"k": "v"},
+		Meta:    "hi",
+		Named:   []levelName{"debug", "info"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestPopulateWithPrefix(t *testing.T) {
+	type BillingForm struct {
+		Street  string            `formfield:"street"`
+		City    string            `formfield:"city"`
+		Contact Contact           `formfield:"contact"`
+		Notes   []string          `formfield:"notes"`
+		Extra   map[string]string `formfield:"extra"`
+	}
+
+	formData := url.Values{
+		"billing.street":        {"1 Main St"},
+		"billing.city":          {"NYC"},
+		"billing.contact.email": {"billing@example.com"},
+		"billing.notes":         {"a", "b"},
+		"billing.extra[po]":     {"42"},
+		"shipping.street":       {"9 Elm St"},
+		"shipping.city":         {"Boston"},
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{name: "plain prefix", prefix: "billing"},
+		{name: "trailing separator", prefix: "billing."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result BillingForm
+			if err := PopulateWithPrefix(req, &result, tt.prefix); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := BillingForm{
+				Street:  "1 Main St",
+				City:    "NYC",
+				Contact: Contact{Email: "billing@example.com"},
+				Notes:   []string{"a", "b"},
+				Extra:   map[string]string{"po": "42"},
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("got %+v, want %+v", result, expected)
+			}
+		})
+	}
+
+	t.Run("keys outside the section are ignored", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("city=LA&email=top@example.com&billing.street=1+Main+St"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result BillingForm
+		if err := PopulateWithPrefix(req, &result, "billing"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := BillingForm{Street: "1 Main St"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("other section", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result BillingForm
+		if err := PopulateWithPrefix(req, &result, "shipping"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Street != "9 Elm St" || result.City != "Boston" {
+			t.Errorf("got %+v", result)
+		}
+	})
+
+	t.Run("invalid destination", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(""))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result BillingForm
+		if err := PopulateWithPrefix(req, result, "billing"); err == nil {
+			t.Error("expected error for non-pointer destination")
+		}
+	})
+}
+
+func TestPopulate_DotNotationOverridesJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		theme    string
+		lang     string
+	}{
+		{
+			name: "dot key overrides JSON field",
+			formData: url.Values{
+				"settings":       {`{"theme":"dark","lang":"en"}`},
+				"settings.theme": {"light"},
+			},
+			theme: "light",
+			lang:  "en",
+		},
+		{
+			name: "dot keys fill fields missing from JSON",
+			formData: url.Values{
+				"settings":      {`{"theme":"dark"}`},
+				"settings.lang": {"fr"},
+			},
+			theme: "dark",
+			lang:  "fr",
+		},
+		{
+			name:     "JSON only",
+			formData: url.Values{"settings": {`{"theme":"dark","lang":"en"}`}},
+			theme:    "dark",
+			lang:     "en",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Profile
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Settings.Theme != tt.theme || result.Settings.Language != tt.lang {
+				t.Errorf("got %+v, want theme %q and lang %q", result.Settings, tt.theme, tt.lang)
+			}
+		})
+	}
+}
+
+func TestGetFiles(t *testing.T) {
+	newRequest := func(t *testing.T, files map[string][]string) *http.Request {
+		t.Helper()
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		for _, field := range []string{"files", "files[]"} {
+			for _, name := range files[field] {
+				fw, err := w.CreateFormFile(field, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fw.Write([]byte(name))
+			}
+		}
+		w.Close()
+
+		req := httptest.NewRequest("POST", "/", &b)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	filenames := func(headers []*multipart.FileHeader) []string {
+		var names []string
+		for _, h := range headers {
+			names = append(names, h.Filename)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name  string
+		files map[string][]string
+		want  []string
+	}{
+		{
+			name:  "repeated key",
+			files: map[string][]string{"files": {"a.txt", "b.txt"}},
+			want:  []string{"a.txt", "b.txt"},
+		},
+		{
+			name:  "bracket suffix",
+			files: map[string][]string{"files[]": {"a.txt", "b.txt", "c.txt"}},
+			want:  []string{"a.txt", "b.txt", "c.txt"},
+		},
+		{
+			name:  "both forms",
+			files: map[string][]string{"files": {"a.txt"}, "files[]": {"b.txt"}},
+			want:  []string{"a.txt", "b.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, tt.files)
+			req.ParseMultipartForm(32 << 20)
+
+			headers, err := GetFiles(req, "files")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := filenames(headers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetFiles: got %v, want %v", got, tt.want)
+			}
+
+			var result struct {
+				Files   []*multipart.FileHeader `formfield:"files"`
+				Uploads []FileUpload            `formfield:"files"`
+				First   *multipart.FileHeader   `formfield:"files"`
+			}
+			if err := Populate(newRequest(t, tt.files), &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := filenames(result.Files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Files: got %v, want %v", got, tt.want)
+			}
+			if len(result.Uploads) != len(tt.want) {
+				t.Errorf("Uploads: got %d, want %d", len(result.Uploads), len(tt.want))
+			}
+			if result.First == nil || result.First.Filename != tt.want[0] {
+				t.Errorf("First: got %+v, want %s", result.First, tt.want[0])
+			}
+		})
+	}
+
+	t.Run("no files", func(t *testing.T) {
+		req := newRequest(t, nil)
+		req.ParseMultipartForm(32 << 20)
+
+		if _, err := GetFiles(req, "files"); !errors.Is(err, http.ErrMissingFile) {
+			t.Errorf("expected http.ErrMissingFile, got %v", err)
+		}
+	})
+
+	t.Run("unparsed form", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(""))
+		if _, err := GetFiles(req, "files"); err == nil {
+			t.Error("expected error without multipart form data")
+		}
+	})
+}
+
+func TestPopulate_RawMessageFields(t *testing.T) {
+	type Form struct {
+		Payload json.RawMessage   `formfield:"payload"`
+		Ptr     *json.RawMessage  `formfield:"ptr"`
+		Events  []json.RawMessage `formfield:"events"`
+	}
+
+	formData := url.Values{
+		"payload": {`{"b": 2, "a": [1, 2]}`},
+		"ptr":     {"plain text"},
+		"events":  {`{"type":"click"}`, "42"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Form
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(result.Payload) != `{"b": 2, "a": [1, 2]}` {
+		t.Errorf("Payload: got %s, want the JSON verbatim", result.Payload)
+	}
+	if result.Ptr == nil || string(*result.Ptr) != "plain text" {
+		t.Errorf("Ptr: got %v, want the plain string verbatim", result.Ptr)
+	}
+	if len(result.Events) != 2 || string(result.Events[0]) != `{"type":"click"}` || string(result.Events[1]) != "42" {
+		t.Errorf("Events: got %q", result.Events)
+	}
+
+	values, err := Encode(result)
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if got := values.Get("payload"); got != `{"b": 2, "a": [1, 2]}` {
+		t.Errorf("Encode payload: got %q", got)
+	}
+	if got := values["events"]; !reflect.DeepEqual(got, []string{`{"type":"click"}`, "42"}) {
+		t.Errorf("Encode events: got %q", got)
+	}
+}
+
+func TestPopulateWithOptions_DecimalComma(t *testing.T) {
+	type Form struct {
+		Price  float64   `formfield:"price"`
+		Ratios []float32 `formfield:"ratios"`
+		Count  int       `formfield:"count"`
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		strip    bool
+		expected Form
+		wantErr  bool
+	}{
+		{name: "decimal comma", body: "price=3,14", expected: Form{Price: 3.14}},
+		{name: "dot still accepted", body: "price=2.5", expected: Form{Price: 2.5}},
+		{name: "slice elements", body: "ratios=0,5&ratios=1,25", expected: Form{Ratios: []float32{0.5, 1.25}}},
+		{name: "thousands dots without stripping", body: "price=1.234,56", wantErr: true},
+		{name: "thousands dots with stripping", body: "price=1.234,56", strip: true, expected: Form{Price: 1234.56}},
+		{name: "spaces with stripping", body: "price=1+234%2C5", strip: true, expected: Form{Price: 1234.5}},
+		{name: "integers with stripping", body: "count=1.234.567", strip: true, expected: Form{Count: 1234567}},
+		{name: "several commas", body: "price=1,234,56", strip: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts := DefaultOptions()
+			opts.DecimalComma = true
+			opts.StripNumberSeparators = tt.strip
+
+			var result Form
+			err := PopulateWithOptions(req, &result, opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("price=3,14"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err == nil {
+			t.Errorf("expected error for decimal comma, got %+v", result)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	type Form struct {
+		Name      string             `formfield:"name"`
+		Age       int                `formfield:"age"`
+		Nickname  *string            `formfield:"nickname"`
+		Tags      []string           `formfield:"tags"`
+		Labels    map[string]string  `formfield:"labels"`
+		Addresses map[string]Address `formfield:"addresses"`
+		Contact   Contact            `formfield:"contact"`
+	}
+
+	merge := func(t *testing.T, dest *Form, body string) {
+		t.Helper()
+		req := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := Merge(req, dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	nick := "JD"
+	result := Form{
+		Name:      "John",
+		Age:       30,
+		Nickname:  &nick,
+		Tags:      []string{"a"},
+		Labels:    map[string]string{"env": "prod"},
+		Addresses: map[string]Address{"home": {Street: "1 Main St", City: "NYC"}},
+		Contact:   Contact{Phone: "555"},
+	}
+
+	merge(t, &result, "age=31&name=&labels[team]=core&addresses[home].city=Boston&contact.email=j@example.com")
+	merge(t, &result, "tags=b&tags=c&labels=env:staging&addresses[work].city=Denver&nickname=")
+
+	newNick := "JD"
+	expected := Form{
+		Name:     "John",
+		Age:      31,
+		Nickname: &newNick,
+		Tags:     []string{"b", "c"},
+		Labels:   map[string]string{"env": "staging", "team": "core"},
+		Addresses: map[string]Address{
+			"home": {Street: "1 Main St", City: "Boston"},
+			"work": {City: "Denver"},
+		},
+		Contact: Contact{Phone: "555", Email: "j@example.com"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+
+	t.Run("bools not posted are kept with CheckboxSemantics", func(t *testing.T) {
+		saved := DefaultOptions()
+		t.Cleanup(func() { SetDefaultOptions(saved) })
+		opts := DefaultOptions()
+		opts.CheckboxSemantics = true
+		SetDefaultOptions(opts)
+
+		var dest struct {
+			Name   string `formfield:"name"`
+			Active bool   `formfield:"active"`
+		}
+		dest.Active = true
+		req := httptest.NewRequest("PATCH", "/", strings.NewReader("name=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := Merge(req, &dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.Name != "x" || !dest.Active {
+			t.Errorf("got %+v, want Active left true", dest)
+		}
+	})
+
+	t.Run("Populate replaces maps", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("labels[team]=core"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		dest := Form{Labels: map[string]string{"env": "prod"}}
+		if err := Populate(req, &dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dest.Labels, map[string]string{"team": "core"}) {
+			t.Errorf("got %v, want only the posted label", dest.Labels)
+		}
+	})
+}
+
+func TestFieldNames(t *testing.T) {
+	type node struct {
+		Value int   `formfield:"value"`
+		Next  *node `formfield:"next"`
+	}
+	type Everything struct {
+		Person
+		Profile  *Profile          `formfield:"profile"`
+		Shipping Address           `formfield:",inline"`
+		Billing  Address           `formfield:"billing"`
+		Homepage url.URL           `formfield:"homepage"`
+		Created  time.Time         `formfield:"created"`
+		Labels   map[string]string `formfield:"labels"`
+		Stops    []Address         `formfield:"stops"`
+		Avatar   FileUpload        `formfield:"avatar"`
+		List     node              `formfield:"list"`
+		Skipped  string            `formfield:"-"`
+		NoTag    string
+		private  string `formfield:"private"`
+	}
+
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{
+			name:  "Person",
+			value: Person{},
+			want:  []string{"name", "age", "street", "city", "zip", "contact.phone", "contact.email"},
+		},
+		{
+			name:  "Profile pointer",
+			value: &Profile{},
+			want:  []string{"bio", "hobbies", "settings.theme", "settings.lang"},
+		},
+		{
+			name:  "skipped fields",
+			value: reflect.TypeOf(StructWithSkippedField{}),
+			want:  []string{"public"},
+		},
+		{
+			name:  "everything",
+			value: Everything{private: "unused"},
+			want: []string{
+				"name", "age", "street", "city", "zip", "contact.phone", "contact.email",
+				"profile.bio", "profile.hobbies", "profile.settings.theme", "profile.settings.lang",
+				"street", "city", "zip",
+				"billing.street", "billing.city", "billing.zip",
+				"homepage", "created", "labels", "stops", "avatar",
+				"list.value", "list.next",
+			},
+		},
+		{
+			name:  "not a struct",
+			value: 42,
+			want:  nil,
+		},
+		{
+			name:  "nil",
+			value: nil,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FieldNames(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPopulate_SetMaps(t *testing.T) {
+	type Form struct {
+		Perms    map[string]bool  `formfield:"perms" mapmode:"set"`
+		Days     map[int]bool     `formfield:"days" mapmode:"set"`
+		Optional *map[string]bool `formfield:"optional" mapmode:"set"`
+		Settings map[string]bool  `formfield:"settings"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Form
+		wantErr  bool
+	}{
+		{
+			name:     "each value becomes a key",
+			formData: url.Values{"perms": {"read", "write"}},
+			expected: Form{Perms: map[string]bool{"read": true, "write": true}},
+		},
+		{
+			name:     "values with colons are keys too",
+			formData: url.Values{"perms": {"admin:all"}},
+			expected: Form{Perms: map[string]bool{"admin:all": true}},
+		},
+		{
+			name:     "empty placeholder is skipped",
+			formData: url.Values{"perms": {"", "read"}},
+			expected: Form{Perms: map[string]bool{"read": true}},
+		},
+		{
+			name:     "non-string keys",
+			formData: url.Values{"days": {"1", "5"}},
+			expected: Form{Days: map[int]bool{1: true, 5: true}},
+		},
+		{
+			name:     "pointer to map",
+			formData: url.Values{"optional": {"beta"}},
+			expected: Form{Optional: &map[string]bool{"beta": true}},
+		},
+		{
+			name:     "untagged bool map keeps colon format",
+			formData: url.Values{"settings": {"dark:true", "compact:false"}},
+			expected: Form{Settings: map[string]bool{"dark": true, "compact": false}},
+		},
+		{
+			name:     "nothing checked",
+			formData: url.Values{},
+			expected: Form{},
+		},
+		{
+			name:     "invalid key",
+			formData: url.Values{"days": {"monday"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("encode round trip", func(t *testing.T) {
+		in := Form{Perms: map[string]bool{"write": true, "read": true, "delete": false}}
+		values, err := Encode(in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := values["perms"]; !reflect.DeepEqual(got, []string{"read", "write"}) {
+			t.Errorf("encoded perms = %v, want [read write]", got)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var unknown struct {
+			Perms map[string]bool `formfield:"perms" mapmode:"list"`
+		}
+		var notBool struct {
+			Perms map[string]string `formfield:"perms" mapmode:"set"`
+		}
+		req := httptest.NewRequest("GET", "/?perms=read", nil)
+
+		var tagErr *StructTagError
+		if err := Populate(req, &unknown); !errors.As(err, &tagErr) || tagErr.Tag != "mapmode" {
+			t.Errorf("unknown mode: got %v, want a mapmode StructTagError", err)
+		}
+		if err := Populate(req, &notBool); !errors.As(err, &tagErr) || tagErr.Tag != "mapmode" {
+			t.Errorf("non-bool map: got %v, want a mapmode StructTagError", err)
+		}
+	})
+}
+
+func TestHasFieldAndFieldValue(t *testing.T) {
+	t.Run("urlencoded", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?page=2", strings.NewReader("name=John&name=Jane&note="))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := req.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name      string
+			wantValue string
+			wantOK    bool
+		}{
+			{name: "name", wantValue: "John", wantOK: true},
+			{name: "note", wantValue: "", wantOK: true},
+			{name: "page", wantValue: "2", wantOK: true},
+			{name: "missing", wantValue: "", wantOK: false},
+		}
+		for _, tt := range tests {
+			if got := HasField(req, tt.name); got != tt.wantOK {
+				t.Errorf("HasField(%q) = %v, want %v", tt.name, got, tt.wantOK)
+			}
+			value, ok := FieldValue(req, tt.name)
+			if value != tt.wantValue || ok != tt.wantOK {
+				t.Errorf("FieldValue(%q) = %q, %v, want %q, %v", tt.name, value, ok, tt.wantValue, tt.wantOK)
+			}
+		}
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		w.WriteField("title", "Report")
+		fw, err := w.CreateFormFile("upload", "a.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte("data"))
+		w.Close()
+
+		req := httptest.NewRequest("POST", "/", &b)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			t.Fatal(err)
+		}
+		// Only the multipart values, as when a caller parsed the body
+		// themselves without filling r.Form.
+		req.Form = nil
+
+		if value, ok := FieldValue(req, "title"); value != "Report" || !ok {
+			t.Errorf("FieldValue(title) = %q, %v, want Report, true", value, ok)
+		}
+		if HasField(req, "upload") {
+			t.Error("HasField(upload) = true, want false for a file part")
+		}
+		if HasField(req, "missing") {
+			t.Error("HasField(missing) = true, want false")
+		}
+	})
+
+	t.Run("unparsed request", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name=John", nil)
+		if HasField(req, "name") {
+			t.Error("HasField on an unparsed request = true, want false")
+		}
+	})
+}
+
+func TestPopulate_BigNumbers(t *testing.T) {
+	type Form struct {
+		Balance big.Int    `formfield:"balance"`
+		Supply  *big.Int   `formfield:"supply"`
+		Rate    big.Float  `formfield:"rate"`
+		Price   *big.Float `formfield:"price"`
+	}
+
+	const (
+		beyondInt64 = "123456789012345678901234567890"
+		precise     = "3.14159265358979323846264338327950288"
+	)
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		opts     Options
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "integers beyond int64",
+			formData: url.Values{"balance": {beyondInt64}, "supply": {"-" + beyondInt64}},
+			want:     map[string]string{"balance": beyondInt64, "supply": "-" + beyondInt64},
+		},
+		{
+			name:     "high precision floats",
+			formData: url.Values{"rate": {precise}, "price": {"0.1"}},
+			want:     map[string]string{"rate": precise, "price": "0.1"},
+		},
+		{
+			name:     "separators stripped",
+			formData: url.Values{"balance": {"1.000.000.000.000.000.000.000"}, "rate": {"1.234,5"}},
+			opts:     Options{StripNumberSeparators: true, DecimalComma: true},
+			want:     map[string]string{"balance": "1000000000000000000000", "rate": "1234.5"},
+		},
+		{
+			name:     "empty form leaves nil pointers",
+			formData: url.Values{},
+			want:     map[string]string{"balance": "0", "supply": "<nil>", "rate": "0", "price": "<nil>"},
+		},
+		{
+			name:     "invalid integer",
+			formData: url.Values{"balance": {"12.5"}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid float",
+			formData: url.Values{"price": {"cheap"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := PopulateWithOptions(req, &result, tt.opts)
+			if tt.wantErr {
+				var bindErr *BindError
+				if !errors.As(err, &bindErr) {
+					t.Errorf("expected a BindError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := map[string]string{
+				"balance": result.Balance.String(),
+				"supply":  result.Supply.String(),
+				"rate":    result.Rate.Text('g', -1),
+				"price":   "<nil>",
+			}
+			if result.Price != nil {
+				got["price"] = result.Price.Text('g', -1)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s: got %s, want %s", key, got[key], want)
+				}
+			}
+		})
+	}
+
+	t.Run("encode round trip", func(t *testing.T) {
+		var src Form
+		src.Balance.SetString(beyondInt64, 10)
+		src.Rate.SetPrec(200).SetString(precise)
+
+		values, err := Encode(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := values.Get("balance"); got != beyondInt64 {
+			t.Errorf("encoded balance = %s, want %s", got, beyondInt64)
+		}
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var dst Form
+		if err := Populate(req, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Balance.Cmp(&src.Balance) != 0 {
+			t.Errorf("round trip balance: got %v, want %v", &dst.Balance, &src.Balance)
+		}
+		if got := dst.Rate.Text('g', -1); got != precise {
+			t.Errorf("round trip rate: got %s, want %s", got, precise)
+		}
+	})
+}
+
+func TestPopulate_StrictJSONStructs(t *testing.T) {
+	type Form struct {
+		Profile
+		Contacts []Contact `formfield:"contacts"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		strict   bool
+		wantErr  string
+	}{
+		{
+			name:     "extra key ignored by default",
+			formData: url.Values{"settings": {`{"theme":"dark","color":"red"}`}},
+		},
+		{
+			name:     "extra key rejected when strict",
+			formData: url.Values{"settings": {`{"theme":"dark","color":"red"}`}},
+			strict:   true,
+			wantErr:  `unknown field "color"`,
+		},
+		{
+			name:     "known keys accepted when strict",
+			formData: url.Values{"settings": {`{"theme":"dark","lang":"en"}`}},
+			strict:   true,
+		},
+		{
+			name:     "slice elements checked when strict",
+			formData: url.Values{"contacts": {`{"Phone":"555","Fax":"556"}`}},
+			strict:   true,
+			wantErr:  `unknown field "Fax"`,
+		},
+		{
+			name:     "trailing data rejected when strict",
+			formData: url.Values{"settings": {`{"theme":"dark"} {}`}},
+			strict:   true,
+			wantErr:  "unexpected data after JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts := DefaultOptions()
+			opts.StrictJSONStructs = tt.strict
+
+			var result Form
+			err := PopulateWithOptions(req, &result, opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Settings.Theme != "dark" {
+				t.Errorf("theme = %q, want dark", result.Settings.Theme)
+			}
+		})
+	}
+}
+
+type auditInfo struct {
+	CreatedBy string `formfield:"created_by"`
+	Revision  int    `formfield:"revision"`
+	note      string `formfield:"note"`
+}
+
+type pageMeta struct {
+	Title string `formfield:"title"`
+	auditInfo
+}
+
+func TestPopulate_UnexportedStructTypes(t *testing.T) {
+	type Form struct {
+		Name string `formfield:"name"`
+		auditInfo
+		Meta pageMeta `formfield:"meta"`
+	}
+
+	values := url.Values{
+		"name":            {"Doc"},
+		"created_by":      {"alice"},
+		"note":            {"ignored"},
+		"meta.title":      {"Home"},
+		"meta.created_by": {"bob"},
+		"meta.revision":   {"3"},
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// The embedded auditInfo can't be set as a whole, but its exported
+	// fields are promoted and can.
+	if reflect.ValueOf(&Form{}).Elem().FieldByName("auditInfo").CanSet() {
+		t.Fatal("expected the embedded unexported struct to be unsettable")
+	}
+
+	var result Form
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Form{
+		Name:      "Doc",
+		auditInfo: auditInfo{CreatedBy: "alice"},
+		Meta:      pageMeta{Title: "Home", auditInfo: auditInfo{CreatedBy: "bob", Revision: 3}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+
+	t.Run("encode", func(t *testing.T) {
+		encoded, err := Encode(expected)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if encoded.Get("created_by") != "alice" || encoded.Get("meta.created_by") != "bob" {
+			t.Errorf("got %v, want promoted fields encoded", encoded)
+		}
+	})
+
+	t.Run("field names", func(t *testing.T) {
+		want := []string{"name", "created_by", "revision", "meta.title", "meta.created_by", "meta.revision"}
+		if got := FieldNames(Form{}); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("tags checked", func(t *testing.T) {
+		type limits struct {
+			Max int `formfield:"max" min:"zero"`
+		}
+		var form struct {
+			limits
+		}
+		var tagErr *StructTagError
+		if err := Populate(httptest.NewRequest("GET", "/?max=1", nil), &form); !errors.As(err, &tagErr) {
+			t.Errorf("got %v, want a StructTagError", err)
+		}
+	})
+}
+
+func TestPopulate_StrictParse(t *testing.T) {
+	type Form struct {
+		Name string `formfield:"name"`
+		Age  int    `formfield:"age"`
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		strict      bool
+		wantErr     string
+		want        Form
+	}{
+		{
+			name:        "well formed body",
+			body:        "name=John&age=30",
+			contentType: "application/x-www-form-urlencoded",
+			strict:      true,
+			want:        Form{Name: "John", Age: 30},
+		},
+		{
+			name:        "bad escape reported without strict",
+			body:        "name=%zz&age=30",
+			contentType: "application/x-www-form-urlencoded",
+			wantErr:     `invalid URL escape "%zz"`,
+		},
+		{
+			name:        "bad escape reported with strict",
+			body:        "name=%zz&age=30",
+			contentType: "application/x-www-form-urlencoded",
+			strict:      true,
+			wantErr:     `invalid URL escape "%zz"`,
+		},
+		{
+			name:        "value without a key tolerated",
+			body:        "=x&name=John",
+			contentType: "application/x-www-form-urlencoded",
+			want:        Form{Name: "John"},
+		},
+		{
+			name:        "value without a key rejected",
+			body:        "=x&name=John",
+			contentType: "application/x-www-form-urlencoded",
+			strict:      true,
+			wantErr:     "value without a key",
+		},
+		{
+			name: "missing content type ignores body",
+			body: "name=John",
+			want: Form{},
+		},
+		{
+			name:    "missing content type rejected",
+			body:    "name=John",
+			strict:  true,
+			wantErr: `unsupported Content-Type ""`,
+		},
+		{
+			name:        "non-form content type rejected",
+			body:        `{"name":"John"}`,
+			contentType: "application/json",
+			strict:      true,
+			wantErr:     `unsupported Content-Type "application/json"`,
+		},
+		{
+			name:        "invalid content type rejected",
+			body:        "name=John",
+			contentType: "application/x-www-form-urlencoded; =broken",
+			strict:      true,
+			wantErr:     "invalid Content-Type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			opts := DefaultOptions()
+			opts.StrictParse = tt.strict
+
+			var result Form
+			err := PopulateWithOptions(req, &result, opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("got %+v, want %+v", result, tt.want)
+			}
+		})
+	}
+
+	t.Run("GET without a body", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name=John", nil)
+		var result Form
+		if err := PopulateWithOptions(req, &result, Options{StrictParse: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Name != "John" {
+			t.Errorf("got %+v, want Name John", result)
+		}
+	})
+}
+
+func TestPopulate_Aliases(t *testing.T) {
+	type Form struct {
+		Email string   `formfield:"email" aliases:"e-mail, mail"`
+		Phone *string  `formfield:"phone" aliases:"tel"`
+		Tags  []string `formfield:"tags" aliases:"labels"`
+	}
+
+	phone := "555"
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Form
+	}{
+		{
+			name:     "alias only",
+			formData: url.Values{"e-mail": {"a@example.com"}, "tel": {"555"}, "labels": {"x", "y"}},
+			expected: Form{Email: "a@example.com", Phone: &phone, Tags: []string{"x", "y"}},
+		},
+		{
+			name:     "primary wins over alias",
+			formData: url.Values{"email": {"primary@example.com"}, "e-mail": {"alias@example.com"}},
+			expected: Form{Email: "primary@example.com"},
+		},
+		{
+			name:     "aliases tried in order",
+			formData: url.Values{"mail": {"second@example.com"}, "e-mail": {"first@example.com"}},
+			expected: Form{Email: "first@example.com"},
+		},
+		{
+			name:     "later alias used when earlier ones are absent",
+			formData: url.Values{"mail": {"second@example.com"}},
+			expected: Form{Email: "second@example.com"},
+		},
+		{
+			name:     "no key present",
+			formData: url.Values{"other": {"x"}},
+			expected: Form{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("nested alias", func(t *testing.T) {
+		var result struct {
+			Billing struct {
+				Zip string `formfield:"zip" aliases:"postcode"`
+			} `formfield:"billing"`
+		}
+		req := httptest.NewRequest("GET", "/?billing.postcode=12345", nil)
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Billing.Zip != "12345" {
+			t.Errorf("got %q, want 12345", result.Billing.Zip)
+		}
+	})
+
+	t.Run("empty alias", func(t *testing.T) {
+		var result struct {
+			Email string `formfield:"email" aliases:"mail,"`
+		}
+		var tagErr *StructTagError
+		if err := Populate(httptest.NewRequest("GET", "/", nil), &result); !errors.As(err, &tagErr) || tagErr.Tag != "aliases" {
+			t.Errorf("got %v, want an aliases StructTagError", err)
+		}
+	})
+}
+
+func TestPopulate_ISODurations(t *testing.T) {
+	type Form struct {
+		Timeout  time.Duration  `formfield:"timeout" durationformat:"iso8601"`
+		Interval *time.Duration `formfield:"interval" durationformat:"iso8601"`
+		Raw      time.Duration  `formfield:"raw"`
+	}
+
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT1H", want: time.Hour},
+		{value: "PT1H30M", want: 90 * time.Minute},
+		{value: "P1DT2H", want: 26 * time.Hour},
+		{value: "P2W", want: 14 * 24 * time.Hour},
+		{value: "P1D", want: 24 * time.Hour},
+		{value: "PT0.5S", want: 500 * time.Millisecond},
+		{value: "PT1,25S", want: 1250 * time.Millisecond},
+		{value: "PT90M", want: 90 * time.Minute},
+		{value: "-PT15M", want: -15 * time.Minute},
+		{value: "P1DT2H3M4S", want: 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{value: "1h30m", wantErr: true},
+		{value: "P", wantErr: true},
+		{value: "PT", wantErr: true},
+		{value: "P1DT", wantErr: true},
+		{value: "P1Y", wantErr: true},
+		{value: "P1M", wantErr: true},
+		{value: "PT1M1H", wantErr: true},
+		{value: "PT1H1H", wantErr: true},
+		{value: "P1H", wantErr: true},
+		{value: "PTH", wantErr: true},
+		{value: "PT1HT1M", wantErr: true},
+		{value: "P99999999999W", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			values := url.Values{"timeout": {tt.value}, "interval": {tt.value}}
+			req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", result.Timeout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Timeout != tt.want {
+				t.Errorf("timeout = %v, want %v", result.Timeout, tt.want)
+			}
+			if result.Interval == nil || *result.Interval != tt.want {
+				t.Errorf("interval = %v, want %v", result.Interval, tt.want)
+			}
+		})
+	}
+
+	t.Run("untagged durations stay numeric", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?raw=1500", nil)
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Raw != 1500 {
+			t.Errorf("raw = %v, want 1.5µs", result.Raw)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var unknown struct {
+			Timeout time.Duration `formfield:"timeout" durationformat:"rfc"`
+		}
+		var notDuration struct {
+			Timeout int64 `formfield:"timeout" durationformat:"iso8601"`
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+
+		var tagErr *StructTagError
+		if err := Populate(req, &unknown); !errors.As(err, &tagErr) || tagErr.Tag != "durationformat" {
+			t.Errorf("unknown format: got %v, want a durationformat StructTagError", err)
+		}
+		if err := Populate(req, &notDuration); !errors.As(err, &tagErr) || tagErr.Tag != "durationformat" {
+			t.Errorf("non-duration field: got %v, want a durationformat StructTagError", err)
+		}
+	})
+}
+
+func TestMustPopulate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name=John&age=30", nil)
+		var result Person
+		MustPopulate(req, &result)
+		if result.Name != "John" || result.Age != 30 {
+			t.Errorf("got %+v, want Name John and Age 30", result)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		dest    any
+		wantErr string
+	}{
+		{
+			name:    "non-pointer target",
+			req:     httptest.NewRequest("GET", "/?name=John", nil),
+			dest:    Person{},
+			wantErr: "former: MustPopulate: dest must be a pointer to a struct",
+		},
+		{
+			name:    "bind error",
+			req:     httptest.NewRequest("GET", "/?age=old", nil),
+			dest:    &Person{},
+			wantErr: "former: MustPopulate: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				err, ok := r.(error)
+				if !ok {
+					t.Fatalf("recovered %v, want an error", r)
+				}
+				if !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("got %q, want prefix %q", err, tt.wantErr)
+				}
+				if errors.Unwrap(err) == nil {
+					t.Error("panic value does not wrap the Populate error")
+				}
+			}()
+			MustPopulate(tt.req, tt.dest)
+		})
+	}
+}
+
+func TestPopulate_ByteArrays(t *testing.T) {
+	type Form struct {
+		Token  [8]byte  `formfield:"token"`
+		Code   *[4]byte `formfield:"code"`
+		Scores [3]int   `formfield:"scores"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Form
+	}{
+		{
+			name:     "exact length",
+			formData: url.Values{"token": {"ABCDEFGH"}},
+			expected: Form{Token: [8]byte{'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H'}},
+		},
+		{
+			name:     "short value is zero padded",
+			formData: url.Values{"token": {"abc"}},
+			expected: Form{Token: [8]byte{'a', 'b', 'c'}},
+		},
+		{
+			name:     "long value is truncated",
+			formData: url.Values{"token": {"0123456789"}},
+			expected: Form{Token: [8]byte{'0', '1', '2', '3', '4', '5', '6', '7'}},
+		},
+		{
+			name:     "multibyte characters keep their bytes",
+			formData: url.Values{"code": {"é!"}},
+			expected: Form{Code: &[4]byte{0xc3, 0xa9, '!'}},
+		},
+		{
+			name:     "repeated numbers fill one element each",
+			formData: url.Values{"token": {"1", "2", "3"}},
+			expected: Form{Token: [8]byte{1, 2, 3}},
+		},
+		{
+			name:     "a lone number fills the first element",
+			formData: url.Values{"token": {"7"}},
+			expected: Form{Token: [8]byte{7}},
+		},
+		{
+			name:     "other arrays still take one value per element",
+			formData: url.Values{"scores": {"1", "2", "3"}},
+			expected: Form{Scores: [3]int{1, 2, 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("encode", func(t *testing.T) {
+		values, err := Encode(Form{Token: [8]byte{'a', 'b', 'c'}, Code: &[4]byte{'w', 'x', 'y', 'z'}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if values.Get("token") != "abc" || values.Get("code") != "wxyz" {
+			t.Errorf("got %v, want token abc and code wxyz", values)
+		}
+	})
+
+	t.Run("numeric token round trip", func(t *testing.T) {
+		original := Form{Token: [8]byte{'7'}}
+		values, err := Encode(original)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, original) {
+			t.Errorf("got %+v, want %+v", result, original)
+		}
+	})
+}
+
+func TestSetDefaultOptions(t *testing.T) {
+	saved := DefaultOptions()
+	t.Cleanup(func() { SetDefaultOptions(saved) })
+
+	type Form struct {
+		Active  bool    `formfield:"active"`
+		Count   int     `formfield:"count"`
+		Contact Contact `formfield:"contact"`
+	}
+
+	trueValues := []string{"yes"}
+	opts := DefaultOptions()
+	opts.TrueValues = trueValues
+	opts.StrictBool = true
+	opts.StripNumberSeparators = true
+	opts.NestingSeparator = "_"
+	SetDefaultOptions(opts)
+	trueValues[0] = "changed"
+
+	if got := DefaultOptions(); got.NestingSeparator != "_" || !slices.Equal(got.TrueValues, []string{"yes"}) {
+		t.Fatalf("DefaultOptions() = %+v, want the registered options", got)
+	}
+
+	values := url.Values{"active": {"yes"}, "count": {"1,000"}, "contact_phone": {"555"}}
+	want := Form{Active: true, Count: 1000, Contact: Contact{Phone: "555"}}
+
+	t.Run("Populate", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != want {
+			t.Errorf("got %+v, want %+v", result, want)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		result := Form{Contact: Contact{Email: "kept@example.com"}}
+		if err := Merge(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := want
+		want.Contact.Email = "kept@example.com"
+		if result != want {
+			t.Errorf("got %+v, want %+v", result, want)
+		}
+	})
+
+	t.Run("strict bool applies", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?active=maybe", nil)
+		var result Form
+		if err := Populate(req, &result); err == nil {
+			t.Error("expected an error for an unrecognized bool with StrictBool")
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		SetDefaultOptions(saved)
+		req := httptest.NewRequest("GET", "/?contact.phone=555", nil)
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Contact.Phone != "555" {
+			t.Errorf("got %+v, want the built-in dot separator", result)
+		}
+	})
+}
+
+func TestSetDefaultOptions_RequestOptions(t *testing.T) {
+	saved := DefaultOptions()
+	t.Cleanup(func() { SetDefaultOptions(saved) })
+
+	type Form struct {
+		Name string `formfield:"name"`
+		Role string `formfield:"role"`
+	}
+
+	opts := DefaultOptions()
+	opts.PostFormOnly = true
+	SetDefaultOptions(opts)
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/?role=admin", strings.NewReader("name=Jane"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+	want := Form{Name: "Jane"}
+
+	entryPoints := []struct {
+		name     string
+		populate func(r *http.Request, dest *Form) error
+	}{
+		{"Populate", func(r *http.Request, dest *Form) error { return Populate(r, dest) }},
+		{"Merge", func(r *http.Request, dest *Form) error { return Merge(r, dest) }},
+		{"PopulateWithPrefix", func(r *http.Request, dest *Form) error { return PopulateWithPrefix(r, dest, "") }},
+		{"PopulateReport", func(r *http.Request, dest *Form) error {
+			_, err := PopulateReport(r, dest)
+			return err
+		}},
+		{"PopulateValue", func(r *http.Request, dest *Form) error {
+			return PopulateValue(r, reflect.ValueOf(dest).Elem())
+		}},
+	}
+
+	for _, ep := range entryPoints {
+		t.Run(ep.name, func(t *testing.T) {
+			var result Form
+			if err := ep.populate(newRequest(), &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != want {
+				t.Errorf("got %+v, want %+v", result, want)
+			}
+		})
+	}
+
+	t.Run("StrictParse", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.StrictParse = true
+		SetDefaultOptions(opts)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=Jane"))
+		req.Header.Set("Content-Type", "text/plain")
+
+		var result Form
+		if err := Merge(req, &result); err == nil {
+			t.Error("expected an error for a text/plain body with StrictParse")
+		}
+	})
+}
+
+func TestPopulate_IncludeCookies(t *testing.T) {
+	type Form struct {
+		Name      string `formfield:"name"`
+		CSRFToken string `formfield:"csrf_token"`
+		Theme     string `formfield:"theme"`
+		Email     string `formfield:"email" aliases:"mail"`
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		cookies  []*http.Cookie
+		include  bool
+		expected Form
+	}{
+		{
+			name:     "cookie fills missing key",
+			body:     "name=John",
+			cookies:  []*http.Cookie{{Name: "csrf_token", Value: "abc123"}},
+			include:  true,
+			expected: Form{Name: "John", CSRFToken: "abc123"},
+		},
+		{
+			name:     "form value wins over cookie",
+			body:     "name=John&csrf_token=fromform",
+			cookies:  []*http.Cookie{{Name: "csrf_token", Value: "abc123"}},
+			include:  true,
+			expected: Form{Name: "John", CSRFToken: "fromform"},
+		},
+		{
+			name:     "alias wins over cookie",
+			body:     "mail=form@example.com",
+			cookies:  []*http.Cookie{{Name: "email", Value: "cookie@example.com"}},
+			include:  true,
+			expected: Form{Email: "form@example.com"},
+		},
+		{
+			name:     "first cookie with the name is used",
+			cookies:  []*http.Cookie{{Name: "theme", Value: "dark"}, {Name: "theme", Value: "light"}},
+			include:  true,
+			expected: Form{Theme: "dark"},
+		},
+		{
+			name:     "cookies ignored by default",
+			body:     "name=John",
+			cookies:  []*http.Cookie{{Name: "csrf_token", Value: "abc123"}},
+			expected: Form{Name: "John"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+
+			opts := DefaultOptions()
+			opts.IncludeCookies = tt.include
+
+			var result Form
+			if err := PopulateWithOptions(req, &result, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("cookies are not unknown fields", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name=John", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "xyz"})
+
+		opts := DefaultOptions()
+		opts.IncludeCookies = true
+		opts.DisallowUnknownFields = true
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestPopulate_CoerceBool(t *testing.T) {
+	type Form struct {
+		Newsletter bool   `formfield:"newsletter" coerce:"bool"`
+		Terms      *bool  `formfield:"terms" coerce:"bool"`
+		Days       []bool `formfield:"days" coerce:"bool"`
+		Plain      bool   `formfield:"plain"`
+	}
+
+	yes, no := true, false
+	tests := []struct {
+		name     string
+		formData url.Values
+		opts     func(*Options)
+		expected Form
+		wantErr  bool
+	}{
+		{
+			name:     "yes and no words",
+			formData: url.Values{"newsletter": {"Yes"}, "terms": {"no"}, "days": {"y", "n", "enabled", "off"}},
+			expected: Form{Newsletter: true, Terms: &no, Days: []bool{true, false, true, false}},
+		},
+		{
+			name:     "standard values still work",
+			formData: url.Values{"newsletter": {"true"}, "terms": {"0"}},
+			expected: Form{Newsletter: true, Terms: &no},
+		},
+		{
+			name:     "untagged field is not coerced",
+			formData: url.Values{"plain": {"yes"}},
+			expected: Form{},
+		},
+		{
+			name:     "coerced words pass StrictBool",
+			formData: url.Values{"newsletter": {"yes"}},
+			opts:     func(o *Options) { o.StrictBool = true },
+			expected: Form{Newsletter: true},
+		},
+		{
+			name:     "unknown words still fail StrictBool",
+			formData: url.Values{"newsletter": {"maybe"}},
+			opts:     func(o *Options) { o.StrictBool = true },
+			wantErr:  true,
+		},
+		{
+			name:     "custom sets combine with coercion",
+			formData: url.Values{"newsletter": {"sí"}, "terms": {"yes"}, "plain": {"sí"}},
+			opts:     func(o *Options) { o.TrueValues = []string{"sí"} },
+			expected: Form{Newsletter: true, Terms: &yes, Plain: true},
+		},
+		{
+			name:     "custom sets win over coercion",
+			formData: url.Values{"newsletter": {"no"}, "terms": {"yes"}},
+			opts: func(o *Options) {
+				o.TrueValues = []string{"no"}
+				o.FalseValues = []string{"yes"}
+			},
+			expected: Form{Newsletter: true, Terms: &no},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts := DefaultOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+
+			var result Form
+			err := PopulateWithOptions(req, &result, opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var unknown struct {
+			Active bool `formfield:"active" coerce:"int"`
+		}
+		var notBool struct {
+			Name string `formfield:"name" coerce:"bool"`
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+
+		var tagErr *StructTagError
+		if err := Populate(req, &unknown); !errors.As(err, &tagErr) || tagErr.Tag != "coerce" {
+			t.Errorf("unknown type: got %v, want a coerce StructTagError", err)
+		}
+		if err := Populate(req, &notBool); !errors.As(err, &tagErr) || tagErr.Tag != "coerce" {
+			t.Errorf("non-bool field: got %v, want a coerce StructTagError", err)
+		}
+	})
+}
+
+func TestPopulate_SliceFromJSONArray(t *testing.T) {
+	type Form struct {
+		IDs      []int      `formfield:"ids"`
+		Tags     []string   `formfield:"tags"`
+		Contacts []Contact  `formfield:"contacts"`
+		Ptr      *[]float64 `formfield:"ptr"`
+		IPs      []net.IP   `formfield:"ips"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Form
+		wantErr  bool
+	}{
+		{
+			name:     "ints",
+			formData: url.Values{"ids": {"[1, 2, 3]"}},
+			expected: Form{IDs: []int{1, 2, 3}},
+		},
+		{
+			name:     "strings",
+			formData: url.Values{"tags": {`["go","web"]`}},
+			expected: Form{Tags: []string{"go", "web"}},
+		},
+		{
+			name:     "empty array",
+			formData: url.Values{"tags": {"[]"}},
+			expected: Form{Tags: []string{}},
+		},
+		{
+			name:     "structs",
+			formData: url.Values{"contacts": {`[{"Phone":"555"},{"Email":"a@example.com"}]`}},
+			expected: Form{Contacts: []Contact{{Phone: "555"}, {Email: "a@example.com"}}},
+		},
+		{
+			name:     "pointer to slice",
+			formData: url.Values{"ptr": {"[1.5,2]"}},
+			expected: Form{Ptr: &[]float64{1.5, 2}},
+		},
+		{
+			name:     "repeated keys unchanged",
+			formData: url.Values{"ids": {"1", "2"}, "tags": {"[a]", "b"}},
+			expected: Form{IDs: []int{1, 2}, Tags: []string{"[a]", "b"}},
+		},
+		{
+			name:     "bracketed text that is not JSON",
+			formData: url.Values{"tags": {"[draft]"}},
+			expected: Form{Tags: []string{"[draft]"}},
+		},
+		{
+			name:     "value types keep per-element parsing",
+			formData: url.Values{"ips": {"10.0.0.1"}},
+			expected: Form{IPs: []net.IP{net.ParseIP("10.0.0.1")}},
+		},
+		{
+			name:     "wrong element type",
+			formData: url.Values{"ids": {`["one"]`}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("strict JSON structs apply to elements", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?contacts="+url.QueryEscape(`[{"Fax":"1"}]`), nil)
+		opts := DefaultOptions()
+		opts.StrictJSONStructs = true
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err == nil || !strings.Contains(err.Error(), `unknown field "Fax"`) {
+			t.Errorf("got %v, want an unknown field error", err)
+		}
+	})
+}
+
+func TestPopulate_MaxFileSize(t *testing.T) {
+	type Form struct {
+		Title       string                  `formfield:"title"`
+		Avatar      *multipart.FileHeader   `formfield:"avatar"`
+		Attachments []FileUpload            `formfield:"attachments"`
+		Extra       []*multipart.FileHeader `formfield:"extra"`
+	}
+
+	type upload struct{ field, name, data string }
+	newRequest := func(t *testing.T, uploads []upload) *http.Request {
+		t.Helper()
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		w.WriteField("title", "Report")
+		for _, u := range uploads {
+			fw, err := w.CreateFormFile(u.field, u.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write([]byte(u.data))
+		}
+		w.Close()
+
+		req := httptest.NewRequest("POST", "/", &b)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	tests := []struct {
+		name    string
+		limit   int64
+		uploads []upload
+		wantErr string
+		wantKey string
+	}{
+		{
+			name:  "all files within the limit",
+			limit: 10,
+			uploads: []upload{
+				{"avatar", "me.png", "12345"},
+				{"attachments", "a.txt", "1234567890"},
+				{"attachments", "b.txt", "1"},
+			},
+		},
+		{
+			name:  "one oversized file among several",
+			limit: 10,
+			uploads: []upload{
+				{"avatar", "me.png", "12345"},
+				{"attachments", "a.txt", "123"},
+				{"attachments", "big.txt", "12345678901"},
+				{"attachments", "c.txt", "1"},
+			},
+			wantErr: `failed to set field Attachments: file "big.txt" is 11 bytes, over the limit of 10`,
+			wantKey: "attachments",
+		},
+		{
+			name:    "single file field",
+			limit:   4,
+			uploads: []upload{{"avatar", "me.png", "12345"}},
+			wantErr: `failed to set field Avatar: file "me.png" is 5 bytes, over the limit of 4`,
+			wantKey: "avatar",
+		},
+		{
+			name:    "bracketed key",
+			limit:   4,
+			uploads: []upload{{"extra[]", "x.bin", "12345"}},
+			wantErr: `failed to set field Extra: file "x.bin" is 5 bytes, over the limit of 4`,
+			wantKey: "extra",
+		},
+		{
+			name:    "no limit by default",
+			uploads: []upload{{"avatar", "me.png", strings.Repeat("x", 1<<10)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, tt.uploads)
+			opts := DefaultOptions()
+			opts.MaxFileSize = tt.limit
+
+			var result Form
+			err := PopulateWithOptions(req, &result, opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Title != "Report" || result.Avatar == nil {
+					t.Errorf("got %+v, want the title and avatar bound", result)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) || bindErr.Key != tt.wantKey {
+				t.Errorf("got %#v, want a BindError for key %q", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestPopulate_StripBOM(t *testing.T) {
+	type Form struct {
+		Name  string   `formfield:"name"`
+		Age   int      `formfield:"age"`
+		Price float64  `formfield:"price"`
+		Tags  []string `formfield:"tags"`
+	}
+
+	const bom = "\uFEFF"
+	body := bom + "name=" + url.QueryEscape(bom+"John") +
+		"&age=" + url.QueryEscape(bom+"30") +
+		"&price=" + url.QueryEscape(bom+"9.5") +
+		"&tags=" + url.QueryEscape(bom+"a") + "&tags=b"
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("stripped", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.StripBOM = true
+
+		var result Form
+		if err := PopulateWithOptions(newRequest(), &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := Form{Name: "John", Age: 30, Price: 9.5, Tags: []string{"a", "b"}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("numeric value fails without the option", func(t *testing.T) {
+		var result Form
+		err := Populate(newRequest(), &result)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "Age" {
+			t.Errorf("got %v, want a BindError for Age", err)
+		}
+	})
+
+	t.Run("BOM only stripped at the start", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name="+url.QueryEscape("Jo"+bom+"hn"), nil)
+		opts := DefaultOptions()
+		opts.StripBOM = true
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Name != "Jo"+bom+"hn" {
+			t.Errorf("got %q, want the inner BOM kept", result.Name)
+		}
+	})
+}
+
+func TestPopulateWithOptions_SkipField(t *testing.T) {
+	type Form struct {
+		Name         string `formfield:"name"`
+		InternalRole string `formfield:"role"`
+		InternalID   int    `formfield:"id" required:"true"`
+	}
+
+	req := httptest.NewRequest("GET", "/?name=John&role=admin&id=7", nil)
+	opts := DefaultOptions()
+	opts.SkipField = func(field reflect.StructField) bool {
+		return strings.HasPrefix(field.Name, "Internal")
+	}
+
+	result := Form{InternalRole: "user"}
+	if err := PopulateWithOptions(req, &result, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Form{Name: "John", InternalRole: "user"}
+	if result != expected {
+		t.Errorf("got %+v, want %+v", result, expected)
+	}
+}
+
+func TestPopulate_PointerToCollection(t *testing.T) {
+	type Form struct {
+		IDs    *[]int             `formfield:"ids"`
+		Labels *map[string]string `formfield:"labels"`
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantIDs    []int
+		wantLabels map[string]string
+	}{
+		{
+			name:    "slice values",
+			query:   "ids=1&ids=2",
+			wantIDs: []int{1, 2},
+		},
+		{
+			name:       "map bracket entries",
+			query:      "labels[env]=prod&labels[team]=core",
+			wantLabels: map[string]string{"env": "prod", "team": "core"},
+		},
+		{
+			name:       "map JSON object",
+			query:      "labels=" + url.QueryEscape(`{"env":"prod"}`),
+			wantLabels: map[string]string{"env": "prod"},
+		},
+		{
+			name:  "no values leaves both nil",
+			query: "other=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+			var result Form
+			if err := Populate(req, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantIDs == nil {
+				if result.IDs != nil {
+					t.Errorf("IDs = %v, want nil", *result.IDs)
+				}
+			} else if result.IDs == nil || !reflect.DeepEqual(*result.IDs, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", result.IDs, tt.wantIDs)
+			}
+
+			if tt.wantLabels == nil {
+				if result.Labels != nil {
+					t.Errorf("Labels = %v, want nil", *result.Labels)
+				}
+			} else if result.Labels == nil || !reflect.DeepEqual(*result.Labels, tt.wantLabels) {
+				t.Errorf("Labels = %v, want %v", result.Labels, tt.wantLabels)
+			}
+		})
+	}
+}
+
+func TestPopulate_MapDestination(t *testing.T) {
+	formData := url.Values{
+		"name": {"Jane"},
+		"age":  {"30"},
+		"tags": {"a", "b"},
+	}
+
+	t.Run("nil map", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?page=2", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result map[string]any
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := map[string]any{
+			"name": "Jane",
+			"age":  "30",
+			"tags": []string{"a", "b"},
+			"page": "2",
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %v, want %v", result, expected)
+		}
+	})
+
+	t.Run("existing map keeps other keys", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?"+formData.Encode(), nil)
+
+		result := map[string]any{"source": "web"}
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result["source"] != "web" || result["name"] != "Jane" {
+			t.Errorf("got %v", result)
+		}
+	})
+
+	t.Run("PostFormOnly", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?page=2", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		opts := DefaultOptions()
+		opts.PostFormOnly = true
+
+		var result map[string]any
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := result["page"]; ok {
+			t.Errorf("got query key page in %v", result)
+		}
+	})
+
+	t.Run("other map types are rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?"+formData.Encode(), nil)
+
+		var result map[string]string
+		if err := Populate(req, &result); err == nil {
+			t.Error("expected an error for map[string]string")
+		}
+	})
+
+	t.Run("typed nil pointer is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?"+formData.Encode(), nil)
+
+		type Form struct {
+			Name string `formfield:"name"`
+		}
+		err := Populate(req, (*Form)(nil))
+		if err == nil || err.Error() != "dest must be a pointer to a struct" {
+			t.Errorf("got error %v, want %q", err, "dest must be a pointer to a struct")
+		}
+		if err := Populate(req, (*map[string]any)(nil)); err == nil {
+			t.Error("expected an error for a nil *map[string]any")
+		}
+	})
+}
+
+func TestPopulateWithOptions_NullSentinel(t *testing.T) {
+	type Form struct {
+		Nickname *string   `formfield:"nickname"`
+		Age      *int      `formfield:"age"`
+		Tags     *[]string `formfield:"tags"`
+		Name     string    `formfield:"name"`
+	}
+
+	nickname, age := "Johnny", 30
+	tags := []string{"a"}
+	newForm := func() Form {
+		return Form{Nickname: &nickname, Age: &age, Tags: &tags, Name: "John"}
+	}
+
+	opts := DefaultOptions()
+	opts.NullSentinel = "__null__"
+	opts.PreserveOnEmpty = true
+
+	t.Run("sentinel clears pointers", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?nickname=__null__&age=__null__&tags=__null__", nil)
+
+		result := newForm()
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Nickname != nil || result.Age != nil || result.Tags != nil {
+			t.Errorf("got %+v, want nil pointers", result)
+		}
+	})
+
+	t.Run("absent keys keep pointers", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name=Jane", nil)
+
+		result := newForm()
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Nickname != &nickname || result.Age != &age || result.Tags != &tags {
+			t.Errorf("got %+v, want pointers kept", result)
+		}
+	})
+
+	t.Run("normal value allocates", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?nickname=Jay&age=41", nil)
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Nickname == nil || *result.Nickname != "Jay" || result.Age == nil || *result.Age != 41 {
+			t.Errorf("got %+v", result)
+		}
+	})
+
+	t.Run("non-pointer field takes the sentinel as a value", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name=__null__", nil)
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Name != "__null__" {
+			t.Errorf("Name = %q, want the sentinel kept", result.Name)
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?nickname=__null__", nil)
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Nickname == nil || *result.Nickname != "__null__" {
+			t.Errorf("Nickname = %v, want the sentinel as a value", result.Nickname)
+		}
+	})
+}
+
+func TestPopulate_PercentFormat(t *testing.T) {
+	type Form struct {
+		Rate    float64   `formfield:"rate" numfmt:"percent"`
+		Share   *float32  `formfield:"share" numfmt:"percent"`
+		Weights []float64 `formfield:"weights" numfmt:"percent"`
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected Form
+		wantErr  bool
+	}{
+		{
+			name:     "percent",
+			query:    "rate=45%25",
+			expected: Form{Rate: 0.45},
+		},
+		{
+			name:     "hundred percent",
+			query:    "rate=100%25",
+			expected: Form{Rate: 1},
+		},
+		{
+			name:     "without a sign",
+			query:    "rate=12.5",
+			expected: Form{Rate: 0.125},
+		},
+		{
+			name:     "slice",
+			query:    "weights=25%25&weights=75%25",
+			expected: Form{Weights: []float64{0.25, 0.75}},
+		},
+		{
+			name:    "malformed",
+			query:   "rate=abc%25",
+			wantErr: true,
+		},
+		{
+			name:    "doubled sign",
+			query:   "rate=45%25%25",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr {
+				var bindErr *BindError
+				if !errors.As(err, &bindErr) || bindErr.Field != "Rate" {
+					t.Errorf("got %v, want a BindError for Rate", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("pointer", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?share=50%25", nil)
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Share == nil || *result.Share != 0.5 {
+			t.Errorf("Share = %v, want 0.5", result.Share)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var unknown struct {
+			Rate float64 `formfield:"rate" numfmt:"permille"`
+		}
+		var notFloat struct {
+			Rate int `formfield:"rate" numfmt:"percent"`
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+
+		var tagErr *StructTagError
+		if err := Populate(req, &unknown); !errors.As(err, &tagErr) || tagErr.Tag != "numfmt" {
+			t.Errorf("unknown format: got %v, want a numfmt StructTagError", err)
+		}
+		if err := Populate(req, &notFloat); !errors.As(err, &tagErr) || tagErr.Tag != "numfmt" {
+			t.Errorf("non-float field: got %v, want a numfmt StructTagError", err)
+		}
+	})
+}
+
+func TestPopulateWithOptions_NormalizeKeys(t *testing.T) {
+	type Item struct {
+		Name string `formfield:"name"`
+	}
+	type Form struct {
+		Name    string            `formfield:"name"`
+		Contact Contact           `formfield:"contact"`
+		Labels  map[string]string `formfield:"labels"`
+		Items   []Item            `formfield:"items"`
+		Tags    []string          `formfield:"tags"`
+	}
+
+	formData := url.Values{
+		"NAME":          {"Jane"},
+		"Contact.Email": {"jane@example.com"},
+		"CONTACT.phone": {"555-1234"},
+		"Labels[Env]":   {"prod"},
+		"Items[0].Name": {"Pen"},
+		"Tags":          {"a"},
+		"tags":          {"b"},
+		"CSRF_Token":    {"x"},
+	}
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("lowercase", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.NormalizeKeys = strings.ToLower
+		opts.DisallowUnknownFields = true
+		opts.IgnoreUnknownFields = []string{"csrf_token"}
+
+		var result Form
+		if err := PopulateWithOptions(newRequest(), &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := Form{
+			Name:    "Jane",
+			Contact: Contact{Phone: "555-1234", Email: "jane@example.com"},
+			Labels:  map[string]string{"env": "prod"},
+			Items:   []Item{{Name: "Pen"}},
+			Tags:    []string{"a", "b"},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("custom function", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?contact-email=jane@example.com", nil)
+		opts := DefaultOptions()
+		opts.NormalizeKeys = func(key string) string { return strings.ReplaceAll(key, "-", ".") }
+
+		var result Form
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Contact.Email != "jane@example.com" {
+			t.Errorf("Contact.Email = %q, want jane@example.com", result.Contact.Email)
+		}
+	})
+
+	t.Run("map destination", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.NormalizeKeys = strings.ToLower
+
+		var result map[string]any
+		if err := PopulateWithOptions(newRequest(), &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result["name"] != "Jane" || !reflect.DeepEqual(result["tags"], []string{"a", "b"}) {
+			t.Errorf("got %v", result)
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		var result Form
+		if err := Populate(newRequest(), &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Name != "" || result.Contact.Email != "" || !reflect.DeepEqual(result.Tags, []string{"b"}) {
+			t.Errorf("got %+v, want only exact keys bound", result)
+		}
+	})
+}
+
+// account enforces its invariants through setters used by Options.UseSetters.
+type account struct {
+	Username string `formfield:"username"`
+	Email    string `formfield:"email"`
+	Plan     string `formfield:"plan"`
+}
+
+func (a *account) SetUsername(value string) error {
+	if strings.ContainsAny(value, " @") {
+		return fmt.Errorf("username %q may not contain spaces or @", value)
+	}
+	a.Username = strings.ToLower(value)
+	return nil
+}
+
+// SetPlan has the wrong signature and is not used as a setter.
+func (a *account) SetPlan(value string) {
+	a.Plan = "ignored"
+}
+
+func TestPopulateWithOptions_UseSetters(t *testing.T) {
+	opts := DefaultOptions()
+	opts.UseSetters = true
+
+	t.Run("setter stores the value", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?username=Jane&email=jane@example.com&plan=pro", nil)
+
+		var result account
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := account{Username: "jane", Email: "jane@example.com", Plan: "pro"}
+		if result != expected {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("setter rejects the value", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?username="+url.QueryEscape("jane doe"), nil)
+
+		var result account
+		err := PopulateWithOptions(req, &result, opts)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "Username" || bindErr.Key != "username" {
+			t.Fatalf("got %v, want a BindError for Username", err)
+		}
+		if !strings.Contains(err.Error(), "may not contain spaces") {
+			t.Errorf("error = %v, want the setter's message", err)
+		}
+		if result.Username != "" {
+			t.Errorf("Username = %q, want it left unset", result.Username)
+		}
+	})
+
+	t.Run("absent value skips the setter", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+
+		result := account{Username: "kept"}
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Username != "kept" {
+			t.Errorf("Username = %q, want kept", result.Username)
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?username=Jane", nil)
+
+		var result account
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Username != "Jane" {
+			t.Errorf("Username = %q, want Jane assigned directly", result.Username)
+		}
+	})
+}
+
+// page is a generic struct, as a library wrapping former might declare.
+type page[T any] struct {
+	Items []T `formfield:"items"`
+	Size  int `formfield:"size"`
+}
+
+// bindGeneric stands in for a generic wrapper around former.
+func bindGeneric[T any](r *http.Request) (*T, error) {
+	return PopulateGeneric[T](r)
+}
+
+func TestPopulateGeneric(t *testing.T) {
+	t.Run("plain struct", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?name=John&age=30&contact.phone=555", nil)
+
+		result, err := PopulateGeneric[Person](req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Name != "John" || result.Age != 30 || result.Contact.Phone != "555" {
+			t.Errorf("got %+v", result)
+		}
+	})
+
+	t.Run("generic struct", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?items=3&items=5&size=2", nil)
+
+		result, err := bindGeneric[page[int]](req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := page[int]{Items: []int{3, 5}, Size: 2}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("got %+v, want %+v", *result, expected)
+		}
+	})
+
+	t.Run("generic struct of structs", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?items[0].phone=555&items[1].email=a@b.c", nil)
+
+		result, err := PopulateGeneric[page[Contact]](req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := page[Contact]{Items: []Contact{{Phone: "555"}, {Email: "a@b.c"}}}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("got %+v, want %+v", *result, expected)
+		}
+	})
+
+	t.Run("bind error", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?size=big", nil)
+
+		result, err := PopulateGeneric[page[string]](req)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "Size" {
+			t.Errorf("got %v, want a BindError for Size", err)
+		}
+		if result != nil {
+			t.Errorf("result = %+v, want nil", result)
+		}
+	})
+
+	t.Run("non-struct type", func(t *testing.T) {
+		if _, err := PopulateGeneric[int](httptest.NewRequest("GET", "/", nil)); err == nil {
+			t.Error("expected an error for a non-struct type")
+		}
+	})
+}
+
+func TestPopulate_MapModeKVCSV(t *testing.T) {
+	type Form struct {
+		Attrs   map[string]string `formfield:"attrs" mapmode:"kvcsv"`
+		Weights map[string]int    `formfield:"weights" mapmode:"kvcsv"`
+	}
+
+	tests := []struct {
+		name     string
+		query    url.Values
+		expected Form
+		wantErr  bool
+	}{
+		{
+			name:     "pairs",
+			query:    url.Values{"attrs": {"color=red,size=large"}},
+			expected: Form{Attrs: map[string]string{"color": "red", "size": "large"}},
+		},
+		{
+			name:     "value containing equals",
+			query:    url.Values{"attrs": {"q=a=b, sort=name"}},
+			expected: Form{Attrs: map[string]string{"q": "a=b", "sort": "name"}},
+		},
+		{
+			name:     "repeated keys and empty pairs",
+			query:    url.Values{"attrs": {"color=red,", "size=large"}},
+			expected: Form{Attrs: map[string]string{"color": "red", "size": "large"}},
+		},
+		{
+			name:     "typed values",
+			query:    url.Values{"weights": {"a=1,b=2"}},
+			expected: Form{Weights: map[string]int{"a": 1, "b": 2}},
+		},
+		{
+			name:    "invalid typed value",
+			query:   url.Values{"weights": {"a=heavy"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/?"+tt.query.Encode(), nil)
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr {
+				var bindErr *BindError
+				if !errors.As(err, &bindErr) || bindErr.Field != "Weights" {
+					t.Errorf("got %v, want a BindError for Weights", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("encode round trip", func(t *testing.T) {
+		original := Form{Attrs: map[string]string{"size": "large", "color": "red"}}
+		values, err := Encode(original)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := values.Get("attrs"); got != "color=red,size=large" {
+			t.Errorf("attrs = %q, want color=red,size=large", got)
+		}
+
+		var result Form
+		if err := Populate(httptest.NewRequest("GET", "/?"+values.Encode(), nil), &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result, original) {
+			t.Errorf("got %+v, want %+v", result, original)
+		}
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		var notMap struct {
+			Attrs string `formfield:"attrs" mapmode:"kvcsv"`
+		}
+		var tagErr *StructTagError
+		if err := Populate(httptest.NewRequest("GET", "/", nil), &notMap); !errors.As(err, &tagErr) || tagErr.Tag != "mapmode" {
+			t.Errorf("got %v, want a mapmode StructTagError", err)
+		}
+	})
+}
+
+func TestPopulateWithOptions_UnescapeHTML(t *testing.T) {
+	type Form struct {
+		Title  string            `formfield:"title"`
+		Tags   []string          `formfield:"tags"`
+		Note   *string           `formfield:"note"`
+		Labels map[string]string `formfield:"labels"`
+	}
+
+	formData := url.Values{
+		"title":         {"Tom &amp; Jerry"},
+		"tags":          {"&lt;b&gt;", "plain"},
+		"note":          {"caf&eacute; &#39;ok&#39;"},
+		"labels[quote]": {"&quot;hi&quot;"},
+	}
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("entities decoded", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.UnescapeHTML = true
+
+		var result Form
+		if err := PopulateWithOptions(newRequest(), &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		note := "café 'ok'"
+		expected := Form{
+			Title:  "Tom & Jerry",
+			Tags:   []string{"<b>", "plain"},
+			Note:   &note,
+			Labels: map[string]string{"quote": `"hi"`},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("kept by default", func(t *testing.T) {
+		var result Form
+		if err := Populate(newRequest(), &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Title != "Tom &amp; Jerry" || result.Tags[0] != "&lt;b&gt;" {
+			t.Errorf("got %+v, want the entities untouched", result)
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package former
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Options configures how PopulateWithOptions binds form values into a struct.
+// The zero value is not the default configuration; use DefaultOptions as the
+// starting point.
+type Options struct {
+	// AutoIntBase lets integer fields accept base prefixes such as "0x1F",
+	// "0o17" and "0b101". It is off by default because base detection also
+	// treats a leading zero as octal, so a value like "08" stops parsing.
+	AutoIntBase bool
+
+	// FallbackFieldName looks a field up by its lowercased Go name when no
+	// value is found under its formfield tag. This helps when tags drift from
+	// the names a client actually posts.
+	FallbackFieldName bool
+
+	// TrueValues and FalseValues are extra spellings accepted for bool
+	// fields, such as "yes" and "no". They are matched case-insensitively
+	// before the standard strconv.ParseBool values.
+	TrueValues  []string
+	FalseValues []string
+
+	// StrictBool makes unrecognized bool values an error. Without it they are
+	// coerced: numbers such as "1.0" are true unless they equal zero, and
+	// anything else is false.
+	StrictBool bool
+
+	// MapBareKeyZero keeps map values that have no ":" delimiter, such as
+	// "flag", storing the key with the element type's zero value. By default
+	// they are dropped. Note that "flag:" is always kept, with its value
+	// parsed from the empty string.
+	MapBareKeyZero bool
+
+	// FlattenEmbedded binds the fields of untagged embedded structs as if
+	// they were declared on the parent. It is on by default. Turn it off when
+	// types are embedded only for method promotion; embedded structs then
+	// bind only when they carry a formfield tag.
+	FlattenEmbedded bool
+
+	// PreserveOnEmpty leaves a field untouched when every value posted for
+	// it is empty, so defaults set on the struct before binding survive
+	// blank inputs. By default an empty value overwrites the field.
+	PreserveOnEmpty bool
+
+	// NestingSeparator joins a nested struct's key to its fields' keys, as
+	// in "contact.email". Set it to "__" to accept "contact__email". An empty
+	// separator is treated as ".".
+	NestingSeparator string
+
+	// UseLastValue binds scalar fields from the last of several values posted
+	// under the same key instead of the first. Slices and arrays still
+	// receive every value.
+	UseLastValue bool
+
+	// StripNumberSeparators removes thousands separators from integer and
+	// float values before parsing, so "1,000" and "1 000" both bind as 1000.
+	// Commas, spaces and non-breaking spaces are removed. Each value is
+	// stripped on its own; repeated keys for slices are unaffected.
+	StripNumberSeparators bool
+
+	// DecimalComma accepts a comma as the decimal separator in float values,
+	// so "3,14" binds as 3.14. Combined with StripNumberSeparators, dots are
+	// then treated as thousands separators instead of commas, and
+	// "1.234,56" binds as 1234.56.
+	DecimalComma bool
+
+	// DisallowUnknownFields makes binding fail when the form holds a key that
+	// no field reads, like json.Decoder.DisallowUnknownFields. It catches
+	// drift between the names a client posts and the struct's tags. Uploaded
+	// files are not checked.
+	DisallowUnknownFields bool
+
+	// IgnoreUnknownFields lists keys that DisallowUnknownFields lets through,
+	// such as a CSRF token or a submit button's name.
+	IgnoreUnknownFields []string
+
+	// StrictJSONStructs makes a JSON object posted for a struct field fail
+	// when it holds a key the struct has no field for, instead of ignoring
+	// it as encoding/json does by default.
+	StrictJSONStructs bool
+
+	// DotOverridesJSON decides which wins when a struct field receives both
+	// a JSON object and dot-notation keys, as in settings={...} alongside
+	// settings.theme=light. It is on by default, applying the dot keys over
+	// the decoded JSON. Turn it off to keep the JSON and ignore the dot
+	// keys, as earlier versions did.
+	DotOverridesJSON bool
+
+	// FieldHook is called with the values posted for a field before they are
+	// parsed, and the values it returns are bound in their place. Use it to
+	// normalize input in one spot, such as lowercasing emails or stripping
+	// formatting from phone numbers. Returning an error aborts binding.
+	FieldHook func(field reflect.StructField, values []string) ([]string, error)
+
+	// SkipField is consulted for every tagged field, and the fields it
+	// returns true for are left untouched, as if tagged formfield:"-". It
+	// suits exclusions that a static tag can't express, such as fields
+	// carrying a custom internal marker.
+	SkipField func(field reflect.StructField) bool
+
+	// RecoverPanics turns a panic raised while binding, whether from
+	// reflection or from a FormUnmarshaler or FieldHook, into an error that
+	// names the field being set. Panics in Validate are not recovered.
+	RecoverPanics bool
+
+	// DecompressBody reads request bodies sent with a gzip or deflate
+	// Content-Encoding by decompressing them before the form is parsed.
+	// It has no effect on forms that were already parsed.
+	DecompressBody bool
+
+	// CheckboxSemantics treats a bool field with no value in the form as an
+	// unchecked checkbox and sets it to false, even if it was true before
+	// binding. Browsers omit unchecked boxes entirely, so without this a
+	// default of true can never be switched off. It wins over
+	// PreserveOnEmpty for bool fields and does not reach *bool fields or
+	// fields of nested pointer structs that received no values.
+	CheckboxSemantics bool
+
+	// PostFormOnly binds from the request body alone, ignoring URL query
+	// parameters. By default the body and query are merged as in
+	// http.Request.Form, so a query parameter can supply a field the body
+	// left out.
+	PostFormOnly bool
+
+	// StrictParse rejects submissions that ParseForm would otherwise accept
+	// in part: a body sent with a missing or non-form Content-Type, which is
+	// ignored, and a value posted without a key, such as "=x". Malformed
+	// escapes are reported either way.
+	StrictParse bool
+
+	// IncludeCookies lets a field bind from the request cookie of the same
+	// name when the form has no value for it, as in double-submit CSRF
+	// schemes that keep the token in a cookie. Form values always win, and
+	// only the first cookie with a given name is used.
+	IncludeCookies bool
+
+	// MaxFileSize rejects any uploaded file bound into a struct field that
+	// is larger than this many bytes, with a BindError naming the field and
+	// the file. It is finer grained than capping the whole body with
+	// http.MaxBytesReader, catching one oversized file among otherwise small
+	// ones. Zero means no limit.
+	MaxFileSize int64
+
+	// StripBOM removes a UTF-8 byte order mark (U+FEFF) from the start of
+	// values before they are parsed, so a value copied from a file with a
+	// BOM still parses as a number. A BOM at the very start of the body,
+	// which ends up in the first key, is looked through as well.
+	StripBOM bool
+
+	// NullSentinel is a value, such as "__null__", that sets a pointer
+	// field to nil when posted for it. A missing key leaves the field alone
+	// under Merge, so the sentinel lets PATCH-style forms clear a value
+	// explicitly. Fields that aren't pointers treat it as an ordinary value.
+	// An empty NullSentinel turns this off.
+	NullSentinel string
+
+	// NormalizeKeys, when set, is applied to every posted key and to every
+	// key derived from the struct's tags before they are compared, so that
+	// binding is insensitive to whatever it normalizes away. Set it to
+	// strings.ToLower to match keys case-insensitively, nested paths and
+	// map keys included: "Contact.Email" then fills contact.email, and
+	// "labels[Env]" binds the map key "env". Posted keys that normalize to
+	// the same key have their values combined. The function is applied to
+	// keys that may already be normalized, so it must be idempotent.
+	NormalizeKeys func(key string) string
+
+	// UseSetters binds a field X through a SetX(string) error method on the
+	// struct's pointer, when one exists, instead of assigning it directly.
+	// The setter receives the posted value and can enforce invariants by
+	// returning an error, which is reported as a BindError for the field.
+	UseSetters bool
+
+	// PathValue reads the path parameter name of r for fields tagged
+	// formpath, reporting whether it was present. Set it to adapt a router
+	// that keeps parameters elsewhere, such as chi.URLParam. By default
+	// http.Request.PathValue is used, and an empty value counts as absent.
+	PathValue func(r *http.Request, name string) (string, bool)
+
+	// EncodeNestedAsJSON makes EncodeWithOptions write each nested struct
+	// as a single JSON value under its key, as in settings={"theme":"dark"},
+	// instead of the default dot-notation keys such as settings.theme=dark.
+	// Populate reads either form back.
+	EncodeNestedAsJSON bool
+
+	// UnescapeHTML decodes HTML entities in values bound into string
+	// fields, so "Tom &amp; Jerry" from a client that escapes its input
+	// twice is stored as "Tom & Jerry". Other field types are unaffected.
+	UnescapeHTML bool
+}
+
+var (
+	defaultOptionsMu sync.RWMutex
+	defaultOptions   = Options{
+		FlattenEmbedded:  true,
+		NestingSeparator: ".",
+		DotOverridesJSON: true,
+	}
+)
+
+// DefaultOptions returns the options used by Populate and the other functions
+// that don't take Options: the built-in defaults, or those last passed to
+// SetDefaultOptions.
+func DefaultOptions() Options {
+	defaultOptionsMu.RLock()
+	defer defaultOptionsMu.RUnlock()
+	return defaultOptions
+}
+
+// SetDefaultOptions replaces the options returned by DefaultOptions, so an
+// application can configure binding once instead of passing Options to every
+// call. The defaults are global to the program, libraries included, so call
+// it during startup before any requests are handled; changing them while
+// requests are in flight leaves it unspecified which options a given call
+// sees. Start from DefaultOptions to keep the settings you don't change.
+func SetDefaultOptions(opts Options) {
+	opts.TrueValues = slices.Clone(opts.TrueValues)
+	opts.FalseValues = slices.Clone(opts.FalseValues)
+	opts.IgnoreUnknownFields = slices.Clone(opts.IgnoreUnknownFields)
+
+	defaultOptionsMu.Lock()
+	defer defaultOptionsMu.Unlock()
+	defaultOptions = opts
+}
@@ -0,0 +1,39 @@
+package former
+
+import "sort"
+
+// Report describes the outcome of a PopulateReport call. Fields are named by
+// their form key, such as "contact.email", so they line up with the keys a
+// client posts.
+type Report struct {
+	// Consumed lists the form keys that were read into the struct, sorted.
+	Consumed []string
+	// Bound lists the fields that received a value, in struct order.
+	Bound []string
+	// Unbound lists the fields that no form value reached, in struct order.
+	// Their values are whatever they held before binding.
+	Unbound []string
+}
+
+// record notes whether the field under key received a value.
+func (d *decoder) record(key string, bound bool) {
+	if d.report == nil {
+		return
+	}
+
+	if bound {
+		d.report.Bound = append(d.report.Bound, key)
+	} else {
+		d.report.Unbound = append(d.report.Unbound, key)
+	}
+}
+
+// finishReport fills in the consumed keys and returns the report.
+func (d *decoder) finishReport() Report {
+	report := *d.report
+	for key := range d.used {
+		report.Consumed = append(report.Consumed, key)
+	}
+	sort.Strings(report.Consumed)
+	return report
+}
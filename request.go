@@ -0,0 +1,348 @@
+package former
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// requestFields maps the "@" names a formfield tag may use to the part of
+// the request they bind, so an audit or logging struct can capture where a
+// submission came from in the same call as its values.
+var requestFields = map[string]func(r *http.Request) string{
+	"@method": func(r *http.Request) string { return r.Method },
+	"@path":   func(r *http.Request) string { return r.URL.Path },
+	"@url":    func(r *http.Request) string { return r.URL.String() },
+}
+
+// isRequestField reports whether a formfield name refers to the request
+// itself rather than a form key.
+func isRequestField(name string) bool {
+	return strings.HasPrefix(name, "@")
+}
+
+// checkRequestField checks that a field tagged with the request field name
+// exists and can hold it: a string, or a url.URL for "@url".
+func checkRequestField(field reflect.StructField, name string) error {
+	if _, ok := requestFields[name]; !ok {
+		return &StructTagError{Field: field.Name, Tag: "formfield", Err: fmt.Errorf("unknown request field %q", name)}
+	}
+	t := derefType(field.Type)
+	if t.Kind() != reflect.String && !(name == "@url" && t == urlType) {
+		return &StructTagError{Field: field.Name, Tag: "formfield", Err: fmt.Errorf("%s requires a string field, got %s", name, t)}
+	}
+	return nil
+}
+
+// setRequestField binds the part of the request named by name, such as
+// "@method", into fieldValue. It does nothing when there is no request, as
+// with PopulateMultipart.
+func (d *decoder) setRequestField(fieldValue reflect.Value, name string) error {
+	if d.request == nil {
+		return nil
+	}
+	return d.setFieldValue(fieldValue, []string{requestFields[name](d.request)})
+}
+
+// valueSources maps the names a source tag may list to a lookup of the
+// values posted under a key in that part of the request. "form" is the form
+// Populate normally reads.
+var valueSources = map[string]func(d *decoder, key string) []string{
+	"form": func(d *decoder, key string) []string {
+		return d.getFormValues(key)
+	},
+	"query": func(d *decoder, key string) []string {
+		if d.request == nil {
+			return nil
+		}
+		values := d.request.URL.Query()[key]
+		if len(values) > 0 {
+			d.markUsed(d.normalizeKey(key))
+		}
+		return values
+	},
+	"header": func(d *decoder, key string) []string {
+		if d.request == nil {
+			return nil
+		}
+		return d.request.Header.Values(key)
+	},
+	"cookie": func(d *decoder, key string) []string {
+		if d.request == nil {
+			return nil
+		}
+		if cookie, err := d.request.Cookie(key); err == nil {
+			return []string{cookie.Value}
+		}
+		return nil
+	},
+}
+
+// parseSources splits a source tag into its source names.
+func parseSources(tag string) []string {
+	sources := strings.Split(tag, ",")
+	for i, source := range sources {
+		sources[i] = strings.TrimSpace(source)
+	}
+	return sources
+}
+
+// checkSourceTag checks that a source tag names only known sources, each
+// at most once.
+func checkSourceTag(field reflect.StructField, tag string) error {
+	sources := parseSources(tag)
+	for i, source := range sources {
+		if _, ok := valueSources[source]; !ok {
+			return &StructTagError{Field: field.Name, Tag: "source", Err: fmt.Errorf("unknown source %q", source)}
+		}
+		if slices.Contains(sources[:i], source) {
+			return &StructTagError{Field: field.Name, Tag: "source", Err: fmt.Errorf("source %q is listed twice", source)}
+		}
+	}
+	return nil
+}
+
+// getFieldValues returns the values for field under key. Without a source
+// tag that is the form; with one, each listed source is tried in order and
+// the first holding a non-empty value wins.
+func (d *decoder) getFieldValues(field reflect.StructField, key string) []string {
+	tag, ok := field.Tag.Lookup("source")
+	if !ok {
+		return d.getFormValues(key)
+	}
+
+	for _, source := range parseSources(tag) {
+		if values := valueSources[source](d, key); !allEmpty(values) {
+			return values
+		}
+	}
+	return nil
+}
+
+// checkHeaderField checks a formheader tag: it must name a header, and the
+// field can't also have a formfield tag.
+func checkHeaderField(field reflect.StructField, formFieldName, header string) error {
+	if strings.TrimSpace(header) == "" {
+		return &StructTagError{Field: field.Name, Tag: "formheader", Err: fmt.Errorf("empty header name")}
+	}
+	if formFieldName != "" {
+		return &StructTagError{Field: field.Name, Tag: "formheader", Err: fmt.Errorf("can't be combined with a formfield tag")}
+	}
+	return nil
+}
+
+// populateHeader binds a field tagged formheader from the request header of
+// that name. Slice fields take every value of the header, with
+// comma-separated lists split into their elements; other fields take the
+// first. It does nothing when there is no request.
+func (d *decoder) populateHeader(field reflect.StructField, fieldValue reflect.Value, header string) error {
+	if d.request == nil {
+		return nil
+	}
+
+	values := d.request.Header.Values(header)
+	if derefType(fieldValue.Type()).Kind() == reflect.Slice && !isValueType(derefType(fieldValue.Type())) {
+		values = splitHeaderList(values)
+	}
+	return d.setRequestValues(field, fieldValue, header, values)
+}
+
+// checkPathField checks that a formpath tag names a parameter and isn't
+// combined with a formfield tag.
+func checkPathField(field reflect.StructField, formFieldName, param string) error {
+	if strings.TrimSpace(param) == "" {
+		return &StructTagError{Field: field.Name, Tag: "formpath", Err: fmt.Errorf("empty path parameter name")}
+	}
+	if formFieldName != "" {
+		return &StructTagError{Field: field.Name, Tag: "formpath", Err: fmt.Errorf("can't be combined with a formfield tag")}
+	}
+	return nil
+}
+
+// populatePath binds a field tagged formpath from the path parameter of that
+// name, read with Options.PathValue or, by default, http.Request.PathValue.
+// It does nothing when there is no request.
+func (d *decoder) populatePath(field reflect.StructField, fieldValue reflect.Value, param string) error {
+	if d.request == nil {
+		return nil
+	}
+
+	var values []string
+	if d.opts.PathValue != nil {
+		if value, ok := d.opts.PathValue(d.request, param); ok {
+			values = []string{value}
+		}
+	} else if value := d.request.PathValue(param); value != "" {
+		values = []string{value}
+	}
+	return d.setRequestValues(field, fieldValue, param, values)
+}
+
+// setRequestValues sets a field bound from outside the form, such as a
+// header, to values, reporting failures under key.
+func (d *decoder) setRequestValues(field reflect.StructField, fieldValue reflect.Value, key string, values []string) error {
+	if d.opts.PreserveOnEmpty && allEmpty(values) {
+		values = nil
+	}
+
+	values, err := d.runFieldHook(field, values)
+	if err != nil {
+		return newBindError(field, key, err)
+	}
+	if len(values) == 0 {
+		d.record(key, false)
+		return nil
+	}
+
+	target := indirect(fieldValue)
+	if err := d.setStructField(field, target, values); err != nil {
+		return newBindError(field, key, err)
+	}
+	if err := validateField(field, target); err != nil {
+		return newBindError(field, key, err)
+	}
+	d.record(key, true)
+	return nil
+}
+
+// splitHeaderList splits comma-separated header values, such as
+// "gzip, br", into their trimmed, non-empty elements.
+func splitHeaderList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// checkIndexField checks that a formindex tag holds a non-negative position
+// and isn't combined with a formfield name.
+func checkIndexField(field reflect.StructField, formFieldName, index string) error {
+	if n, err := strconv.Atoi(index); err != nil || n < 0 {
+		return &StructTagError{Field: field.Name, Tag: "formindex", Err: fmt.Errorf("invalid position %q", index)}
+	}
+	if formFieldName != "" {
+		return &StructTagError{Field: field.Name, Tag: "formindex", Err: fmt.Errorf("can't be combined with a formfield tag")}
+	}
+	return nil
+}
+
+// populateIndex binds a field tagged formindex from the value at that
+// position in the order the form was sent, marking the key it was posted
+// under as read. A position past the last value leaves the field alone.
+func (d *decoder) populateIndex(field reflect.StructField, fieldValue reflect.Value, index string) error {
+	n, _ := strconv.Atoi(index)
+	var values []string
+	if n < len(d.ordered) {
+		values = []string{d.ordered[n].value}
+		d.markUsed(d.normalizeKey(d.ordered[n].key))
+	}
+	return d.setRequestValues(field, fieldValue, "["+index+"]", values)
+}
+
+// usesFormIndex reports whether t or a struct nested in it has a field
+// tagged formindex, so the form's order is only captured when it's needed.
+func usesFormIndex(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup("formindex"); ok {
+			return true
+		}
+		if usesFormIndex(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// formPair is a key of a form and one value posted under it.
+type formPair struct {
+	key, value string
+}
+
+// orderedFormValues returns the values of r's form in the order they were
+// sent: those of a urlencoded body first, then those of the query string
+// unless postOnly is set, the same order r.Form keeps for each key. It must
+// run before the request is parsed. The body is read and put back so that
+// ParseForm still sees it. Once a urlencoded body has been parsed its order
+// is lost, and an error is returned rather than counting the query string
+// alone, which would shift every position. Malformed pairs are skipped here
+// and left to ParseForm to report.
+func orderedFormValues(r *http.Request, postOnly bool) ([]formPair, error) {
+	if r.Form != nil && isURLEncodedBody(r) {
+		return nil, fmt.Errorf("formindex fields need the form's order, which is lost once the body has been parsed")
+	}
+
+	var values []formPair
+	if r.Form == nil && r.Body != nil && r.Body != http.NoBody && isURLEncodedBody(r) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxFormBody+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read form body: %w", err)
+		}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		values = appendOrderedValues(values, string(body))
+	}
+	if !postOnly && r.URL != nil {
+		values = appendOrderedValues(values, r.URL.RawQuery)
+	}
+	return values, nil
+}
+
+// maxFormBody is the most of a urlencoded body orderedFormValues reads,
+// matching the limit ParseForm applies.
+const maxFormBody = 10 << 20
+
+// isURLEncodedBody reports whether ParseForm would read r's body as
+// application/x-www-form-urlencoded.
+func isURLEncodedBody(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return false
+	}
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return ct == "application/x-www-form-urlencoded"
+}
+
+// appendOrderedValues appends the pairs of the encoded query raw to values
+// in the order they appear.
+func appendOrderedValues(values []formPair, raw string) []formPair {
+	for pair := range strings.SplitSeq(raw, "&") {
+		if pair == "" || strings.Contains(pair, ";") {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			continue
+		}
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			continue
+		}
+		values = append(values, formPair{key, value})
+	}
+	return values
+}
@@ -0,0 +1,556 @@
+package former
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPopulate_RequestFields(t *testing.T) {
+	type Audit struct {
+		Method string  `formfield:"@method"`
+		Path   string  `formfield:"@path"`
+		URL    url.URL `formfield:"@url"`
+		Raw    *string `formfield:"@url"`
+		Action string  `formfield:"action"`
+	}
+
+	req := httptest.NewRequest("POST", "/admin/users?id=7", strings.NewReader("action=delete&@method=GET"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result Audit
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Method != "POST" {
+		t.Errorf("Method = %q, want POST", result.Method)
+	}
+	if result.Path != "/admin/users" {
+		t.Errorf("Path = %q, want /admin/users", result.Path)
+	}
+	if result.URL.Path != "/admin/users" || result.URL.RawQuery != "id=7" {
+		t.Errorf("URL = %v, want /admin/users?id=7", &result.URL)
+	}
+	if result.Raw == nil || *result.Raw != "/admin/users?id=7" {
+		t.Errorf("Raw = %v, want /admin/users?id=7", result.Raw)
+	}
+	if result.Action != "delete" {
+		t.Errorf("Action = %q, want delete", result.Action)
+	}
+
+	t.Run("nested struct", func(t *testing.T) {
+		var result struct {
+			Name string `formfield:"name"`
+			Meta struct {
+				Method string `formfield:"@method"`
+			} `formfield:"meta"`
+		}
+		if err := Populate(httptest.NewRequest("GET", "/?name=x", nil), &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Meta.Method != "GET" {
+			t.Errorf("Meta.Method = %q, want GET", result.Meta.Method)
+		}
+	})
+
+	t.Run("not encoded or listed", func(t *testing.T) {
+		values, err := Encode(Audit{Method: "POST", Action: "delete"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(values) != 1 || values.Get("action") != "delete" {
+			t.Errorf("Encode = %v, want only action", values)
+		}
+		if names := FieldNames(Audit{}); len(names) != 1 || names[0] != "action" {
+			t.Errorf("FieldNames = %v, want [action]", names)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			target any
+			want   string
+		}{
+			{
+				name: "unknown name",
+				target: &struct {
+					Host string `formfield:"@host"`
+				}{},
+				want: `invalid formfield tag on field Host: unknown request field "@host"`,
+			},
+			{
+				name: "non-string field",
+				target: &struct {
+					Method int `formfield:"@method"`
+				}{},
+				want: "invalid formfield tag on field Method: @method requires a string field, got int",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := Populate(httptest.NewRequest("GET", "/", nil), tt.target)
+				var tagErr *StructTagError
+				if !errors.As(err, &tagErr) || err.Error() != tt.want {
+					t.Errorf("error = %v, want %q", err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestPopulate_SourceTag(t *testing.T) {
+	type Form struct {
+		Token   string `formfield:"x-api-token" source:"form,header,query"`
+		Page    *int   `formfield:"page" source:"query,form"`
+		Session string `formfield:"session" source:"cookie,form"`
+	}
+
+	newRequest := func(query, body string) *http.Request {
+		req := httptest.NewRequest("POST", "/?"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("form first", func(t *testing.T) {
+		req := newRequest("x-api-token=query", "x-api-token=form")
+		req.Header.Set("X-Api-Token", "header")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Token != "form" {
+			t.Errorf("Token = %q, want form", result.Token)
+		}
+	})
+
+	t.Run("header fallback", func(t *testing.T) {
+		req := newRequest("", "x-api-token=")
+		req.Header.Set("X-Api-Token", "header")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Token != "header" {
+			t.Errorf("Token = %q, want header", result.Token)
+		}
+	})
+
+	t.Run("query over body", func(t *testing.T) {
+		req := newRequest("page=2", "page=5")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Page == nil || *result.Page != 2 {
+			t.Errorf("Page = %v, want 2", result.Page)
+		}
+	})
+
+	t.Run("cookie", func(t *testing.T) {
+		req := newRequest("", "session=form")
+		req.AddCookie(&http.Cookie{Name: "session", Value: "cookie"})
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Session != "cookie" {
+			t.Errorf("Session = %q, want cookie", result.Session)
+		}
+	})
+
+	t.Run("no source has a value", func(t *testing.T) {
+		var result Form
+		if err := Populate(newRequest("", ""), &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Token != "" || result.Page != nil || result.Session != "" {
+			t.Errorf("got %+v, want zero values", result)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var unknown struct {
+			Token string `formfield:"token" source:"form,body"`
+		}
+		var repeated struct {
+			Token string `formfield:"token" source:"header,header"`
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+
+		var tagErr *StructTagError
+		if err := Populate(req, &unknown); !errors.As(err, &tagErr) || tagErr.Tag != "source" {
+			t.Errorf("unknown source: got %v, want a source StructTagError", err)
+		}
+		if err := Populate(req, &repeated); !errors.As(err, &tagErr) || tagErr.Tag != "source" {
+			t.Errorf("repeated source: got %v, want a source StructTagError", err)
+		}
+	})
+}
+
+func TestPopulate_HeaderFields(t *testing.T) {
+	type Form struct {
+		RequestID string   `formheader:"X-Request-Id"`
+		Encodings []string `formheader:"Accept-Encoding"`
+		Retries   *int     `formheader:"X-Retries"`
+		Name      string   `formfield:"name"`
+	}
+
+	t.Run("headers and form in one pass", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=Jane&X-Request-Id=form"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Request-Id", "abc-123")
+		req.Header.Add("Accept-Encoding", "gzip, br")
+		req.Header.Add("Accept-Encoding", "deflate")
+		req.Header.Set("X-Retries", "3")
+
+		var result Form
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.RequestID != "abc-123" {
+			t.Errorf("RequestID = %q, want abc-123", result.RequestID)
+		}
+		if want := []string{"gzip", "br", "deflate"}; !reflect.DeepEqual(result.Encodings, want) {
+			t.Errorf("Encodings = %v, want %v", result.Encodings, want)
+		}
+		if result.Retries == nil || *result.Retries != 3 {
+			t.Errorf("Retries = %v, want 3", result.Retries)
+		}
+		if result.Name != "Jane" {
+			t.Errorf("Name = %q, want Jane", result.Name)
+		}
+	})
+
+	t.Run("missing headers", func(t *testing.T) {
+		var result Form
+		if err := Populate(httptest.NewRequest("GET", "/", nil), &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.RequestID != "" || result.Encodings != nil || result.Retries != nil {
+			t.Errorf("got %+v, want zero values", result)
+		}
+	})
+
+	t.Run("invalid header value", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Retries", "many")
+
+		var result Form
+		err := Populate(req, &result)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "Retries" || bindErr.Key != "X-Retries" {
+			t.Errorf("got %v, want a BindError for X-Retries", err)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var empty struct {
+			ID string `formheader:""`
+		}
+		var combined struct {
+			ID string `formfield:"id" formheader:"X-Id"`
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+
+		var tagErr *StructTagError
+		if err := Populate(req, &empty); !errors.As(err, &tagErr) || tagErr.Tag != "formheader" {
+			t.Errorf("empty header: got %v, want a formheader StructTagError", err)
+		}
+		if err := Populate(req, &combined); !errors.As(err, &tagErr) || tagErr.Tag != "formheader" {
+			t.Errorf("combined tags: got %v, want a formheader StructTagError", err)
+		}
+
+		var badBound struct {
+			ID string `formheader:"X-Id" minlen:"abc"`
+		}
+		if err := Populate(req, &badBound); !errors.As(err, &tagErr) || tagErr.Tag != "minlen" {
+			t.Errorf("malformed minlen: got %v, want a minlen StructTagError", err)
+		}
+	})
+
+	t.Run("validation tags", func(t *testing.T) {
+		type Required struct {
+			ID   string `formheader:"X-Id" required:"true"`
+			Lang string `formheader:"Accept-Language" maxlen:"5"`
+		}
+
+		var result Required
+		err := Populate(httptest.NewRequest("GET", "/", nil), &result)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "ID" || bindErr.Key != "X-Id" {
+			t.Errorf("missing header: got %v, want a BindError for X-Id", err)
+		}
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Id", "1")
+		req.Header.Set("Accept-Language", "en-US,en")
+		err = Populate(req, &result)
+		if !errors.As(err, &bindErr) || bindErr.Key != "Accept-Language" {
+			t.Errorf("long header: got %v, want a BindError for Accept-Language", err)
+		}
+	})
+}
+
+func TestPopulate_PathFields(t *testing.T) {
+	type Form struct {
+		ID   int    `formpath:"id"`
+		Slug string `formpath:"slug"`
+		Name string `formfield:"name"`
+	}
+
+	t.Run("stdlib PathValue", func(t *testing.T) {
+		var result Form
+		var err error
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /users/{id}/{slug}", func(w http.ResponseWriter, r *http.Request) {
+			err = Populate(r, &result)
+		})
+
+		req := httptest.NewRequest("POST", "/users/42/jane-doe", strings.NewReader("name=Jane&id=7"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := Form{ID: 42, Slug: "jane-doe", Name: "Jane"}
+		if result != expected {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("custom extractor", func(t *testing.T) {
+		params := map[string]string{"id": "7"}
+		opts := DefaultOptions()
+		opts.PathValue = func(r *http.Request, name string) (string, bool) {
+			value, ok := params[name]
+			return value, ok
+		}
+
+		var result Form
+		if err := PopulateWithOptions(httptest.NewRequest("GET", "/users/7", nil), &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.ID != 7 || result.Slug != "" {
+			t.Errorf("got %+v, want ID 7 and no slug", result)
+		}
+	})
+
+	t.Run("invalid path value", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users/abc", nil)
+		req.SetPathValue("id", "abc")
+
+		var result Form
+		err := Populate(req, &result)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "ID" || bindErr.Key != "id" {
+			t.Errorf("got %v, want a BindError for id", err)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var combined struct {
+			ID string `formfield:"id" formpath:"id"`
+		}
+		var tagErr *StructTagError
+		if err := Populate(httptest.NewRequest("GET", "/", nil), &combined); !errors.As(err, &tagErr) || tagErr.Tag != "formpath" {
+			t.Errorf("got %v, want a formpath StructTagError", err)
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		var result struct {
+			ID int `formpath:"id" required:"true"`
+		}
+		err := Populate(httptest.NewRequest("GET", "/users/", nil), &result)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Key != "id" {
+			t.Errorf("got %v, want a BindError for id", err)
+		}
+	})
+}
+
+func TestPopulate_IndexFields(t *testing.T) {
+	type Legacy struct {
+		Code   string `formindex:"0"`
+		Amount int    `formindex:"1"`
+		Note   string `formindex:"2"`
+		Extra  string `formindex:"9"`
+		Name   string `formfield:"n"`
+	}
+
+	t.Run("body then query order", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?n=query", strings.NewReader("x=AB%2012&x=250"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Legacy
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := Legacy{Code: "AB 12", Amount: 250, Note: "query", Name: "query"}
+		if result != expected {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("body still parsed", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("n=Jane&a=1&b=2"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Legacy
+		if err := Populate(req, &result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := Legacy{Code: "Jane", Amount: 1, Note: "2", Name: "Jane"}
+		if result != expected {
+			t.Errorf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("post form only", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?c=late", strings.NewReader("a=X&b=3"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		opts := DefaultOptions()
+		opts.PostFormOnly = true
+		var result Legacy
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Code != "X" || result.Amount != 3 || result.Note != "" {
+			t.Errorf("got %+v, want only body values", result)
+		}
+	})
+
+	t.Run("positional keys are not unknown fields", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?first=AB&second=7&n=Jane", nil)
+
+		opts := DefaultOptions()
+		opts.DisallowUnknownFields = true
+		var result Legacy
+		if err := PopulateWithOptions(req, &result, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Code != "AB" || result.Amount != 7 {
+			t.Errorf("got %+v", result)
+		}
+	})
+
+	t.Run("other entry points", func(t *testing.T) {
+		expected := Legacy{Code: "AB", Amount: 7, Note: "Jane", Name: "Jane"}
+		entryPoints := map[string]func(r *http.Request, dest *Legacy) error{
+			"Merge": func(r *http.Request, dest *Legacy) error { return Merge(r, dest) },
+			"PopulateWithPrefix": func(r *http.Request, dest *Legacy) error {
+				return PopulateWithPrefix(r, dest, "")
+			},
+			"PopulateReport": func(r *http.Request, dest *Legacy) error {
+				_, err := PopulateReport(r, dest)
+				return err
+			},
+			"PopulateValue": func(r *http.Request, dest *Legacy) error {
+				return PopulateValue(r, reflect.ValueOf(dest).Elem())
+			},
+		}
+		for name, populate := range entryPoints {
+			t.Run(name, func(t *testing.T) {
+				req := httptest.NewRequest("POST", "/", strings.NewReader("first=AB&second=7&n=Jane"))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+				var result Legacy
+				if err := populate(req, &result); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result != expected {
+					t.Errorf("got %+v, want %+v", result, expected)
+				}
+			})
+		}
+	})
+
+	t.Run("multipart form rejected", func(t *testing.T) {
+		form := &multipart.Form{Value: map[string][]string{"a": {"X"}}}
+
+		var result Legacy
+		if err := PopulateMultipart(form, &result); err == nil {
+			t.Error("expected an error for formindex fields without a request")
+		}
+	})
+
+	t.Run("body already parsed", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?q=9", strings.NewReader("a=1&b=2"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Legacy
+		if err := Validate(req, &result); err != nil {
+			t.Fatalf("Validate: unexpected error: %v", err)
+		}
+		if err := Populate(req, &result); err == nil {
+			t.Errorf("expected an error once the body order is lost, got %+v", result)
+		}
+	})
+
+	t.Run("query only request parsed twice", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?a=X&b=3", nil)
+
+		var first, second Legacy
+		if err := Populate(req, &first); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := Populate(req, &second); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if second != first {
+			t.Errorf("got %+v, want %+v", second, first)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?a=X&b=many", nil)
+
+		var result Legacy
+		var bindErr *BindError
+		if err := Populate(req, &result); !errors.As(err, &bindErr) || bindErr.Field != "Amount" {
+			t.Errorf("got %v, want a BindError for Amount", err)
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		var result struct {
+			Code string `formindex:"0" required:"true"`
+		}
+		err := Populate(httptest.NewRequest("GET", "/", nil), &result)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Key != "[0]" {
+			t.Errorf("got %v, want a BindError for [0]", err)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		var negative struct {
+			A string `formindex:"-1"`
+		}
+		var tagErr *StructTagError
+		if err := Populate(httptest.NewRequest("GET", "/", nil), &negative); !errors.As(err, &tagErr) || tagErr.Tag != "formindex" {
+			t.Errorf("got %v, want a formindex StructTagError", err)
+		}
+
+		var combined struct {
+			A string `formfield:"a" formindex:"0"`
+		}
+		if err := Populate(httptest.NewRequest("GET", "/", nil), &combined); !errors.As(err, &tagErr) || tagErr.Tag != "formindex" {
+			t.Errorf("got %v, want a formindex StructTagError", err)
+		}
+	})
+}
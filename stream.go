@@ -0,0 +1,94 @@
+package former
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// maxStreamValueBytes caps the memory taken by the non-file parts read by
+// PopulateStreaming, matching the limit net/http applies to url-encoded
+// bodies.
+const maxStreamValueBytes = 10 << 20
+
+// PopulateStreaming binds a multipart request without buffering its files.
+// Parts are read one at a time from the body: plain values are collected and
+// bound into dest once the body ends, while every file part is handed to
+// fileHandler as it arrives, so it can be streamed to disk or object storage.
+// A nil fileHandler discards files. File fields in dest are not set, since
+// no *multipart.FileHeader is ever built. Query parameters are bound too,
+// after the body values, as with Populate, unless Options.PostFormOnly is
+// set in DefaultOptions.
+func PopulateStreaming(r *http.Request, dest any, fileHandler func(part *multipart.Part) error) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a struct")
+	}
+
+	opts := DefaultOptions()
+	restore, err := prepareBody(r, opts)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return fmt.Errorf("failed to read multipart form: %w", err)
+	}
+
+	form := make(url.Values)
+	remaining := int64(maxStreamValueBytes)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read multipart form: %w", err)
+		}
+
+		if part.FileName() != "" {
+			if fileHandler != nil {
+				if err := fileHandler(part); err != nil {
+					part.Close()
+					return fmt.Errorf("failed to handle file %q: %w", part.FormName(), err)
+				}
+			}
+			part.Close()
+			continue
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, remaining+1))
+		part.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read multipart form: %w", err)
+		}
+		remaining -= int64(len(value))
+		if remaining < 0 {
+			return fmt.Errorf("multipart values exceed %d bytes", maxStreamValueBytes)
+		}
+		form.Add(part.FormName(), string(value))
+	}
+
+	// As with Populate, multipart values aren't counted by formindex.
+	var ordered []formPair
+	if !opts.PostFormOnly {
+		for key, values := range r.URL.Query() {
+			form[key] = append(form[key], values...)
+		}
+		if usesFormIndex(rv.Elem().Type(), make(map[reflect.Type]bool)) {
+			ordered = appendOrderedValues(ordered, r.URL.RawQuery)
+		}
+	}
+	if err := checkForm(form, opts); err != nil {
+		return err
+	}
+
+	d := &decoder{form: form, opts: opts, request: r, ordered: ordered}
+	return d.bind(rv.Elem())
+}
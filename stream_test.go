@@ -0,0 +1,172 @@
+package former
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPopulateStreaming(t *testing.T) {
+	const fileSize = 8 << 20
+
+	// The body is written through a pipe, so it is never held in memory.
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	go func() {
+		err := func() error {
+			if err := w.WriteField("title", "backup"); err != nil {
+				return err
+			}
+			fw, err := w.CreateFormFile("archive", "backup.tar")
+			if err != nil {
+				return err
+			}
+			if _, err := io.CopyN(fw, zeroReader{}, fileSize); err != nil {
+				return err
+			}
+			if err := w.WriteField("tags", "nightly"); err != nil {
+				return err
+			}
+			if err := w.WriteField("tags", "full"); err != nil {
+				return err
+			}
+			return w.Close()
+		}()
+		pw.CloseWithError(err)
+	}()
+
+	req := httptest.NewRequest("POST", "/?source=cron", pr)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	var result struct {
+		Title  string   `formfield:"title"`
+		Tags   []string `formfield:"tags"`
+		Source string   `formfield:"source"`
+	}
+
+	var files []string
+	var total int64
+	err := PopulateStreaming(req, &result, func(part *multipart.Part) error {
+		files = append(files, part.FormName()+"="+part.FileName())
+		n, err := io.Copy(io.Discard, part)
+		total += n
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Title != "backup" || result.Source != "cron" || strings.Join(result.Tags, ",") != "nightly,full" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(files) != 1 || files[0] != "archive=backup.tar" {
+		t.Errorf("handler saw %v, want [archive=backup.tar]", files)
+	}
+	if total != fileSize {
+		t.Errorf("handler read %d bytes, want %d", total, fileSize)
+	}
+}
+
+func TestPopulateStreaming_Errors(t *testing.T) {
+	body := func(t *testing.T) (*bytes.Buffer, string) {
+		t.Helper()
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		w.WriteField("count", "3")
+		fw, _ := w.CreateFormFile("upload", "a.txt")
+		fw.Write([]byte("data"))
+		w.Close()
+		return &b, w.FormDataContentType()
+	}
+
+	type Form struct {
+		Count int `formfield:"count"`
+	}
+
+	t.Run("handler error", func(t *testing.T) {
+		b, contentType := body(t)
+		req := httptest.NewRequest("POST", "/", b)
+		req.Header.Set("Content-Type", contentType)
+
+		errTooLarge := errors.New("too large")
+		var result Form
+		err := PopulateStreaming(req, &result, func(*multipart.Part) error { return errTooLarge })
+		if !errors.Is(err, errTooLarge) || !strings.Contains(err.Error(), `failed to handle file "upload"`) {
+			t.Errorf("expected wrapped handler error, got %v", err)
+		}
+	})
+
+	t.Run("nil handler discards files", func(t *testing.T) {
+		b, contentType := body(t)
+		req := httptest.NewRequest("POST", "/", b)
+		req.Header.Set("Content-Type", contentType)
+
+		var result Form
+		if err := PopulateStreaming(req, &result, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 3 {
+			t.Errorf("got Count %d, want 3", result.Count)
+		}
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("count=3"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result Form
+		if err := PopulateStreaming(req, &result, nil); err == nil {
+			t.Error("expected error for a non-multipart request")
+		}
+	})
+
+	t.Run("invalid destination", func(t *testing.T) {
+		b, contentType := body(t)
+		req := httptest.NewRequest("POST", "/", b)
+		req.Header.Set("Content-Type", contentType)
+
+		if err := PopulateStreaming(req, Form{}, nil); err == nil {
+			t.Error("expected error for non-pointer destination")
+		}
+	})
+}
+
+func TestPopulateStreaming_PostFormOnly(t *testing.T) {
+	saved := DefaultOptions()
+	t.Cleanup(func() { SetDefaultOptions(saved) })
+
+	opts := DefaultOptions()
+	opts.PostFormOnly = true
+	SetDefaultOptions(opts)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	w.WriteField("name", "Jane")
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/?role=admin", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	var result struct {
+		Name string `formfield:"name"`
+		Role string `formfield:"role"`
+	}
+	if err := PopulateStreaming(req, &result, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Name != "Jane" || result.Role != "" {
+		t.Errorf("got %+v, want only the body value", result)
+	}
+}
+
+// zeroReader yields an endless stream of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
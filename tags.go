@@ -0,0 +1,250 @@
+package former
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// tagOptions is the comma-separated list of options that may follow the name
+// in a formfield tag, e.g. "omitempty" in `formfield:"nick,omitempty"`.
+type tagOptions string
+
+// parseTag splits a formfield tag into the form key and its options.
+func parseTag(tag string) (string, tagOptions) {
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, tagOptions(opts)
+}
+
+// fieldTag returns the form key and options for field. A tag that holds only
+// options, such as `formfield:",omitempty"`, uses the Go field name as the
+// key. Untagged fields return an empty key.
+func fieldTag(field reflect.StructField) (string, tagOptions) {
+	tag, ok := field.Tag.Lookup("formfield")
+	if !ok || tag == "" {
+		return "", ""
+	}
+
+	name, opts := parseTag(tag)
+	if name == "" {
+		name = field.Name
+	}
+	return name, opts
+}
+
+// Contains reports whether the option list includes name.
+func (o tagOptions) Contains(name string) bool {
+	s := string(o)
+	for s != "" {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// knownTagOptions lists the options a formfield tag may carry.
+var knownTagOptions = []string{"omitempty", "inline"}
+
+// checkedStructs caches the result of checkStructTags per struct type.
+var checkedStructs sync.Map
+
+// checkStructTags returns a *StructTagError for the first malformed tag in t
+// or any struct reachable from its fields. Results are cached per type.
+func checkStructTags(t reflect.Type) error {
+	if cached, ok := checkedStructs.Load(t); ok {
+		err, _ := cached.(error)
+		return err
+	}
+
+	err := walkStructTags(t, make(map[reflect.Type]bool))
+	checkedStructs.Store(t, err)
+	return err
+}
+
+func walkStructTags(t reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, opts := fieldTag(field)
+		if name == "-" || !field.IsExported() && (name != "" || !field.Anonymous) {
+			continue
+		}
+		if header, ok := field.Tag.Lookup("formheader"); ok {
+			if err := checkHeaderField(field, name, header); err != nil {
+				return err
+			}
+			if err := checkBoundFieldTags(t, field, opts); err != nil {
+				return err
+			}
+			continue
+		}
+		if param, ok := field.Tag.Lookup("formpath"); ok {
+			if err := checkPathField(field, name, param); err != nil {
+				return err
+			}
+			if err := checkBoundFieldTags(t, field, opts); err != nil {
+				return err
+			}
+			continue
+		}
+		if index, ok := field.Tag.Lookup("formindex"); ok {
+			if err := checkIndexField(field, name, index); err != nil {
+				return err
+			}
+			if err := checkBoundFieldTags(t, field, opts); err != nil {
+				return err
+			}
+			continue
+		}
+		if isRequestField(name) {
+			if err := checkRequestField(field, name); err != nil {
+				return err
+			}
+			continue
+		}
+		if name != "" {
+			if err := checkBoundFieldTags(t, field, opts); err != nil {
+				return err
+			}
+		} else if !field.Anonymous {
+			continue
+		}
+
+		if nested := nestedStructType(field.Type); nested != nil {
+			if err := walkStructTags(nested, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkBoundFieldTags checks the tags of a field of t that binds a value,
+// wherever the value comes from: its formfield options, its parsing and
+// validation tags, and its requiredif tag.
+func checkBoundFieldTags(t reflect.Type, field reflect.StructField, opts tagOptions) error {
+	if err := checkFieldTags(field, opts); err != nil {
+		return err
+	}
+	return checkRequiredIfTag(t, field)
+}
+
+// nestedStructType returns the struct type that binding walks field by field
+// for a field of type t, looking through pointers and the elements of
+// slices, arrays and maps. It returns nil when there is none.
+func nestedStructType(t reflect.Type) reflect.Type {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		t = derefType(t.Elem())
+	}
+	if t.Kind() != reflect.Struct || isValueType(t) {
+		return nil
+	}
+	return t
+}
+
+// derefType strips every level of pointer from t.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// checkFieldTags checks the formfield options and the parsing and
+// validation tags of a single field.
+func checkFieldTags(field reflect.StructField, opts tagOptions) error {
+	for _, opt := range strings.Split(string(opts), ",") {
+		if opt != "" && !slices.Contains(knownTagOptions, opt) {
+			return &StructTagError{Field: field.Name, Tag: "formfield", Err: fmt.Errorf("unknown option %q", opt)}
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("rune"); ok {
+		kind := derefType(field.Type).Kind()
+		if value != "char" {
+			return &StructTagError{Field: field.Name, Tag: "rune", Err: fmt.Errorf("unknown mode %q", value)}
+		}
+		if kind != reflect.Int32 && kind != reflect.Uint8 {
+			return &StructTagError{Field: field.Name, Tag: "rune", Err: fmt.Errorf("requires a rune or byte field, got %s", kind)}
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("coerce"); ok {
+		t := derefType(field.Type)
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = derefType(t.Elem())
+		}
+		if value != "bool" {
+			return &StructTagError{Field: field.Name, Tag: "coerce", Err: fmt.Errorf("unknown type %q", value)}
+		}
+		if t.Kind() != reflect.Bool {
+			return &StructTagError{Field: field.Name, Tag: "coerce", Err: fmt.Errorf("requires a bool field, got %s", t)}
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("durationformat"); ok {
+		if value != "iso8601" {
+			return &StructTagError{Field: field.Name, Tag: "durationformat", Err: fmt.Errorf("unknown format %q", value)}
+		}
+		if t := derefType(field.Type); t != durationType {
+			return &StructTagError{Field: field.Name, Tag: "durationformat", Err: fmt.Errorf("requires a time.Duration field, got %s", t)}
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("numfmt"); ok {
+		t := derefType(field.Type)
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = derefType(t.Elem())
+		}
+		if value != "percent" {
+			return &StructTagError{Field: field.Name, Tag: "numfmt", Err: fmt.Errorf("unknown format %q", value)}
+		}
+		if t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64 {
+			return &StructTagError{Field: field.Name, Tag: "numfmt", Err: fmt.Errorf("requires a float field, got %s", t)}
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("source"); ok {
+		if err := checkSourceTag(field, value); err != nil {
+			return err
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("aliases"); ok {
+		for _, alias := range strings.Split(value, ",") {
+			if strings.TrimSpace(alias) == "" {
+				return &StructTagError{Field: field.Name, Tag: "aliases", Err: fmt.Errorf("empty alias in %q", value)}
+			}
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("mapmode"); ok {
+		t := derefType(field.Type)
+		switch value {
+		case "set":
+			if t.Kind() != reflect.Map || t.Elem().Kind() != reflect.Bool {
+				return &StructTagError{Field: field.Name, Tag: "mapmode", Err: fmt.Errorf("requires a map with bool values, got %s", t)}
+			}
+		case "kvcsv":
+			if t.Kind() != reflect.Map {
+				return &StructTagError{Field: field.Name, Tag: "mapmode", Err: fmt.Errorf("requires a map, got %s", t)}
+			}
+		default:
+			return &StructTagError{Field: field.Name, Tag: "mapmode", Err: fmt.Errorf("unknown mode %q", value)}
+		}
+	}
+
+	return checkValidationTags(field)
+}
@@ -0,0 +1,84 @@
+package former
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		wantName string
+		wantOpts tagOptions
+	}{
+		{"name", "name", ""},
+		{"name,omitempty", "name", "omitempty"},
+		{"name,omitempty,inline", "name", "omitempty,inline"},
+		{",omitempty", "", "omitempty"},
+		{"-", "-", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			name, opts := parseTag(tt.tag)
+			if name != tt.wantName {
+				t.Errorf("name: got %q, want %q", name, tt.wantName)
+			}
+			if opts != tt.wantOpts {
+				t.Errorf("opts: got %q, want %q", opts, tt.wantOpts)
+			}
+		})
+	}
+}
+
+func TestTagOptions_Contains(t *testing.T) {
+	opts := tagOptions("omitempty,inline")
+
+	if !opts.Contains("omitempty") || !opts.Contains("inline") {
+		t.Errorf("expected %q to contain omitempty and inline", opts)
+	}
+	if opts.Contains("omit") || opts.Contains("") {
+		t.Errorf("expected %q not to match partial or empty options", opts)
+	}
+}
+
+func TestFieldTag(t *testing.T) {
+	type Form struct {
+		Named    string `formfield:"named,omitempty"`
+		Bare     string `formfield:",omitempty"`
+		Skipped  string `formfield:"-"`
+		Untagged string
+	}
+
+	formType := reflect.TypeOf(Form{})
+	expected := []string{"named", "Bare", "-", ""}
+
+	for i, want := range expected {
+		name, _ := fieldTag(formType.Field(i))
+		if name != want {
+			t.Errorf("field %s: got %q, want %q", formType.Field(i).Name, name, want)
+		}
+	}
+}
+
+func TestPopulate_TagOptions(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader("named=a&Bare=b&Skipped=c&Untagged=d"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result struct {
+		Named    string `formfield:"named,omitempty"`
+		Bare     string `formfield:",omitempty"`
+		Skipped  string `formfield:"-"`
+		Untagged string
+	}
+	if err := Populate(req, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Named != "a" || result.Bare != "b" || result.Skipped != "" || result.Untagged != "" {
+		t.Errorf("got %+v", result)
+	}
+}
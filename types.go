@@ -0,0 +1,295 @@
+package former
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valueParsers maps standard library types that bind from a single form
+// value to their parser. Without an entry here these types would be treated
+// structurally: structs as nested forms or JSON, and net.IP as a byte slice.
+var valueParsers = map[reflect.Type]func(string) (any, error){
+	urlType: func(s string) (any, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	},
+	reflect.TypeOf(net.IP{}): func(s string) (any, error) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", s)
+		}
+		return ip, nil
+	},
+	reflect.TypeOf(net.IPNet{}): func(s string) (any, error) {
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		return *ipNet, nil
+	},
+	reflect.TypeOf(netip.Addr{}): func(s string) (any, error) {
+		return netip.ParseAddr(s)
+	},
+	reflect.TypeOf(netip.Prefix{}): func(s string) (any, error) {
+		return netip.ParsePrefix(s)
+	},
+	reflect.TypeOf(mail.Address{}): func(s string) (any, error) {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		return *addr, nil
+	},
+	timeType: func(s string) (any, error) {
+		return parseTime(s, "")
+	},
+	rawMessageType: func(s string) (any, error) {
+		return json.RawMessage(s), nil
+	},
+	bigIntType: func(s string) (any, error) {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		return *n, nil
+	},
+	bigFloatType: func(s string) (any, error) {
+		// Keep every posted digit: roughly 3.3 bits per decimal digit,
+		// and never less than a float64 carries.
+		f, _, err := big.ParseFloat(s, 10, uint(max(64, len(s)*4)), big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return *f, nil
+	},
+}
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+// rawMessageType is stored verbatim, whether or not the value is JSON.
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	urlType  = reflect.TypeOf(url.URL{})
+)
+
+// parseTime parses a time.Time according to a timeformat tag: "unix" and
+// "unixmilli" read an integer timestamp, any other value is a layout for
+// time.Parse, and an empty format means time.RFC3339. Several formats
+// separated by "|" are tried in order and the first that parses wins.
+func parseTime(value, format string) (time.Time, error) {
+	if !strings.Contains(format, "|") {
+		return parseTimeFormat(value, format)
+	}
+	layouts := strings.Split(format, "|")
+	for _, layout := range layouts {
+		if t, err := parseTimeFormat(value, layout); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q does not match any of the layouts %q", value, layouts)
+}
+
+// parseTimeFormat parses value according to a single timeformat entry.
+func parseTimeFormat(value, format string) (time.Time, error) {
+	switch format {
+	case "unix", "unixmilli":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if format == "unix" {
+			return time.Unix(n, 0), nil
+		}
+		return time.UnixMilli(n), nil
+	case "":
+		return time.Parse(time.RFC3339, value)
+	default:
+		return time.Parse(format, value)
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// isoDurationUnits lists the ISO 8601 duration designators in the order they
+// must appear, with the length of each. Date designators come before the "T"
+// and time designators after it; years and months are left out because
+// their length varies.
+var isoDurationUnits = []struct {
+	designator byte
+	timePart   bool
+	length     time.Duration
+}{
+	{'W', false, 7 * 24 * time.Hour},
+	{'D', false, 24 * time.Hour},
+	{'H', true, time.Hour},
+	{'M', true, time.Minute},
+	{'S', true, time.Second},
+}
+
+// parseISODuration parses an ISO 8601 duration such as "PT1H30M" or
+// "P1DT2H". A leading sign is allowed, and the last number may have a
+// fraction, as in "PT1.5S". Days count as 24 hours.
+func parseISODuration(value string) (time.Duration, error) {
+	s, neg := value, false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		s, neg = rest, true
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+
+	s, ok := strings.CutPrefix(s, "P")
+	if !ok || s == "" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+	}
+
+	var total time.Duration
+	timePart, next := false, 0
+	for s != "" {
+		if s[0] == 'T' {
+			if timePart {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+			}
+			timePart, s = true, s[1:]
+			continue
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if end <= 0 {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+		}
+		number, designator := s[:end], s[end]
+		s = s[end+1:]
+
+		if !timePart && (designator == 'Y' || designator == 'M') {
+			return 0, fmt.Errorf("ISO 8601 duration %q uses years or months, which have no fixed length", value)
+		}
+
+		unit := -1
+		for i := next; i < len(isoDurationUnits); i++ {
+			if isoDurationUnits[i].designator == designator && isoDurationUnits[i].timePart == timePart {
+				unit = i
+				break
+			}
+		}
+		if unit < 0 {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+		}
+		next = unit + 1
+
+		d, err := scaleDuration(number, isoDurationUnits[unit].length)
+		if err != nil || total > math.MaxInt64-d {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+		}
+		total += d
+	}
+
+	if neg {
+		total = -total
+	}
+	return total, nil
+}
+
+// scaleDuration multiplies a decimal number, which may use a comma or a dot
+// before its fraction, by unit.
+func scaleDuration(number string, unit time.Duration) (time.Duration, error) {
+	whole, frac, _ := strings.Cut(strings.Replace(number, ",", ".", 1), ".")
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || n > int64(math.MaxInt64/unit) {
+		return 0, fmt.Errorf("duration %q out of range", number)
+	}
+	d := time.Duration(n) * unit
+
+	if frac != "" {
+		f, err := strconv.ParseFloat("0."+frac, 64)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(math.Round(f * float64(unit)))
+	}
+	return d, nil
+}
+
+// FormUnmarshaler is implemented by types that bind themselves from the raw
+// form values posted under their key. Unlike encoding.TextUnmarshaler it
+// receives every value, so a type can read a pair such as a minimum and a
+// maximum. It takes precedence over all built-in conversions.
+type FormUnmarshaler interface {
+	UnmarshalForm(values []string) error
+}
+
+var formUnmarshalerType = reflect.TypeOf((*FormUnmarshaler)(nil)).Elem()
+
+// implementsFormUnmarshaler reports whether a pointer to t implements
+// FormUnmarshaler.
+func implementsFormUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(formUnmarshalerType)
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// implementsScanner reports whether a pointer to t implements sql.Scanner.
+func implementsScanner(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(scannerType)
+}
+
+// isValueType reports whether t binds from the values posted under its key
+// rather than by walking its fields or elements.
+func isValueType(t reflect.Type) bool {
+	_, ok := valueParsers[t]
+	return ok || isNullType(t) || implementsFormUnmarshaler(t) || implementsScanner(t)
+}
+
+// isNullType reports whether t is one of the database/sql nullable wrappers,
+// such as sql.NullString or sql.Null[T]. They all hold the value in their
+// first field followed by a Valid flag.
+func isNullType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		t.PkgPath() == "database/sql" &&
+		strings.HasPrefix(t.Name(), "Null") &&
+		t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" &&
+		t.Field(1).Type.Kind() == reflect.Bool
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// implementsJSONUnmarshaler reports whether a pointer to t implements
+// json.Unmarshaler.
+func implementsJSONUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+}
+
+// unmarshalJSONValue hands a form value to u. Values that are not already
+// valid JSON are encoded as a JSON string first, so abc arrives as "abc".
+func unmarshalJSONValue(u json.Unmarshaler, value string) error {
+	raw := []byte(value)
+	if !json.Valid(raw) {
+		quoted, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		raw = quoted
+	}
+	return u.UnmarshalJSON(raw)
+}
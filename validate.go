@@ -0,0 +1,408 @@
+package former
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Validator is implemented by targets that check themselves once binding has
+// finished. Populate calls Validate after every field is set and returns its
+// error, which makes it a natural place for cross-field rules such as
+// matching a password confirmation.
+type Validator interface {
+	Validate() error
+}
+
+// validateField checks a freshly bound value against the validation tags on
+// field. Pointers are followed, and slices and arrays are checked element by
+// element.
+func validateField(field reflect.StructField, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			if err := validateField(field, v.Index(i)); err != nil {
+				return &indexError{index: i, err: err}
+			}
+		}
+		return nil
+	}
+
+	if allowed, ok := field.Tag.Lookup("oneof"); ok {
+		if err := checkOneOf(v, strings.Fields(allowed)); err != nil {
+			return err
+		}
+	}
+
+	if bound, ok := field.Tag.Lookup("min"); ok {
+		if err := checkBound(v, bound, "min"); err != nil {
+			return err
+		}
+	}
+
+	if bound, ok := field.Tag.Lookup("max"); ok {
+		if err := checkBound(v, bound, "max"); err != nil {
+			return err
+		}
+	}
+
+	if bound, ok := field.Tag.Lookup("minlen"); ok {
+		if err := checkLength(v, bound, "minlen"); err != nil {
+			return err
+		}
+	}
+
+	if bound, ok := field.Tag.Lookup("maxlen"); ok {
+		if err := checkLength(v, bound, "maxlen"); err != nil {
+			return err
+		}
+	}
+
+	if pattern, ok := field.Tag.Lookup("pattern"); ok {
+		if err := checkPattern(v, pattern); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRequired enforces the required and requiredif tags of a struct whose
+// fields have all been bound. A field tagged `required:"true"` must not be
+// left at its zero value. A tag such as `requiredif:"type=business"` makes
+// that so only when the sibling field with the form key "type" holds
+// "business".
+func (d *decoder) checkRequired(structValue reflect.Value, prefix string) error {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !structValue.Field(i).IsZero() {
+			continue
+		}
+		key := d.boundKey(field, prefix)
+		if key == "" || d.skipField(field) {
+			continue
+		}
+
+		var err error
+		if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+			err = fmt.Errorf("a value is required")
+		} else if tag, ok := field.Tag.Lookup("requiredif"); ok {
+			key, want, _ := parseRequiredIf(tag)
+			sibling, _ := siblingField(structType, key)
+			if got, fmtErr := formatValue(structValue.FieldByIndex(sibling.Index)); fmtErr == nil && got == want {
+				err = fmt.Errorf("required when %s is %q", key, want)
+			}
+		}
+		if err == nil {
+			continue
+		}
+
+		if bindErr := newBindError(field, key, err); !d.collectError(bindErr) {
+			return bindErr
+		}
+	}
+	return nil
+}
+
+// boundKey returns the key a field of a struct under prefix is reported
+// under: the header, path parameter or position it is read from, or its
+// form key. It returns "" for fields that don't bind a value.
+func (d *decoder) boundKey(field reflect.StructField, prefix string) string {
+	if header, ok := field.Tag.Lookup("formheader"); ok {
+		return header
+	}
+	if param, ok := field.Tag.Lookup("formpath"); ok {
+		return param
+	}
+	if index, ok := field.Tag.Lookup("formindex"); ok {
+		return "[" + index + "]"
+	}
+
+	name, _ := fieldTag(field)
+	if name == "" || name == "-" || isRequestField(name) {
+		return ""
+	}
+	return d.joinKey(prefix, name)
+}
+
+// parseRequiredIf splits a requiredif tag into the sibling's key and the
+// value that makes the field required.
+func parseRequiredIf(tag string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(tag, "=")
+	key = strings.TrimSpace(key)
+	return key, value, ok && key != ""
+}
+
+// siblingField returns the field of t whose form key is key, falling back
+// to the field whose Go name is key.
+func siblingField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if name, _ := fieldTag(t.Field(i)); name == key {
+			return t.Field(i), true
+		}
+	}
+	return t.FieldByName(key)
+}
+
+// checkRequiredIfTag checks that a requiredif tag on a field of t names a
+// sibling holding a single value that can be compared.
+func checkRequiredIfTag(t reflect.Type, field reflect.StructField) error {
+	tag, ok := field.Tag.Lookup("requiredif")
+	if !ok {
+		return nil
+	}
+
+	key, _, ok := parseRequiredIf(tag)
+	if !ok {
+		return &StructTagError{Field: field.Name, Tag: "requiredif", Err: fmt.Errorf("expected key=value, got %q", tag)}
+	}
+	sibling, ok := siblingField(t, key)
+	if !ok {
+		return &StructTagError{Field: field.Name, Tag: "requiredif", Err: fmt.Errorf("no field with key %q", key)}
+	}
+	if _, err := formatValue(reflect.Zero(derefType(sibling.Type))); err != nil {
+		return &StructTagError{Field: field.Name, Tag: "requiredif", Err: fmt.Errorf("field %s cannot be compared: %w", sibling.Name, err)}
+	}
+	return nil
+}
+
+// checkValidationTags checks that the validation tags on field can be
+// applied to its type, so that mistakes surface before any value is bound.
+// The type is looked at the way validateField looks at values: through
+// pointers and at the elements of slices and arrays.
+func checkValidationTags(field reflect.StructField) error {
+	t := derefType(field.Type)
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = derefType(t.Elem())
+	}
+	kind := t.Kind()
+
+	tagError := func(tag string, err error) error {
+		return &StructTagError{Field: field.Name, Tag: tag, Err: err}
+	}
+
+	if allowed, ok := field.Tag.Lookup("oneof"); ok && kind != reflect.String {
+		for _, option := range strings.Fields(allowed) {
+			numeric, err := parseNumber(kind, option)
+			if !numeric {
+				return tagError("oneof", fmt.Errorf("oneof is not supported for %s fields", kind))
+			}
+			if err != nil {
+				return tagError("oneof", fmt.Errorf("invalid oneof option %q: %w", option, err))
+			}
+		}
+	}
+
+	for _, tag := range []string{"min", "max"} {
+		if bound, ok := field.Tag.Lookup(tag); ok {
+			numeric, err := parseNumber(kind, bound)
+			if !numeric {
+				return tagError(tag, fmt.Errorf("%s is not supported for %s fields", tag, kind))
+			}
+			if err != nil {
+				return tagError(tag, fmt.Errorf("invalid %s bound %q: %w", tag, bound, err))
+			}
+		}
+	}
+
+	for _, tag := range []string{"minlen", "maxlen"} {
+		if bound, ok := field.Tag.Lookup(tag); ok {
+			if kind != reflect.String {
+				return tagError(tag, fmt.Errorf("%s is not supported for %s fields", tag, kind))
+			}
+			if _, err := strconv.Atoi(bound); err != nil {
+				return tagError(tag, fmt.Errorf("invalid %s bound %q: %w", tag, bound, err))
+			}
+		}
+	}
+
+	if value, ok := field.Tag.Lookup("required"); ok {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return tagError("required", fmt.Errorf("invalid value %q: %w", value, err))
+		}
+	}
+
+	if pattern, ok := field.Tag.Lookup("pattern"); ok {
+		if kind != reflect.String {
+			return tagError("pattern", fmt.Errorf("pattern is not supported for %s fields", kind))
+		}
+		if _, err := compilePattern(pattern); err != nil {
+			return tagError("pattern", err)
+		}
+	}
+
+	return nil
+}
+
+// parseNumber checks that s parses as a number of the given kind. It
+// reports false when kind is not numeric at all.
+func parseNumber(kind reflect.Kind, s string) (bool, error) {
+	var err error
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(s, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(s, 64)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// patternCache holds compiled pattern tags keyed by their source.
+var patternCache sync.Map
+
+// compilePattern compiles a pattern tag, reusing earlier compilations.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+// checkPattern reports an error unless the string v matches pattern.
+func checkPattern(v reflect.Value, pattern string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("pattern is not supported for %s fields", v.Kind())
+	}
+
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	if !re.MatchString(v.String()) {
+		return fmt.Errorf("%q does not match pattern %s", v.String(), pattern)
+	}
+	return nil
+}
+
+// checkLength compares the length of a string, counted in runes, against
+// the bound from a minlen or maxlen tag.
+func checkLength(v reflect.Value, bound, tag string) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("%s is not supported for %s fields", tag, v.Kind())
+	}
+
+	n, err := strconv.Atoi(bound)
+	if err != nil {
+		return fmt.Errorf("invalid %s bound %q: %w", tag, bound, err)
+	}
+
+	length := utf8.RuneCountInString(v.String())
+	if tag == "minlen" && length < n {
+		return fmt.Errorf("length %d is shorter than the minimum of %d", length, n)
+	}
+	if tag == "maxlen" && length > n {
+		return fmt.Errorf("length %d is longer than the maximum of %d", length, n)
+	}
+	return nil
+}
+
+// checkBound compares a numeric value against the bound from a min or max
+// tag, parsing the bound with the same kind as the value.
+func checkBound(v reflect.Value, bound, tag string) error {
+	var order int
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(bound, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s bound %q: %w", tag, bound, err)
+		}
+		order = cmp.Compare(v.Int(), n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(bound, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s bound %q: %w", tag, bound, err)
+		}
+		order = cmp.Compare(v.Uint(), n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s bound %q: %w", tag, bound, err)
+		}
+		order = cmp.Compare(v.Float(), f)
+
+	default:
+		return fmt.Errorf("%s is not supported for %s fields", tag, v.Kind())
+	}
+
+	if tag == "min" && order < 0 {
+		return fmt.Errorf("%v is less than the minimum of %s", v.Interface(), bound)
+	}
+	if tag == "max" && order > 0 {
+		return fmt.Errorf("%v is greater than the maximum of %s", v.Interface(), bound)
+	}
+	return nil
+}
+
+// checkOneOf reports an error unless v equals one of the allowed values.
+// Numeric fields compare parsed values, so "1.50" matches an allowed "1.5".
+// Empty strings are accepted.
+func checkOneOf(v reflect.Value, allowed []string) error {
+	if v.Kind() == reflect.String && v.String() == "" {
+		return nil
+	}
+
+	for _, option := range allowed {
+		var match bool
+
+		switch v.Kind() {
+		case reflect.String:
+			match = v.String() == option
+
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(option, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid oneof option %q: %w", option, err)
+			}
+			match = v.Int() == n
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(option, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid oneof option %q: %w", option, err)
+			}
+			match = v.Uint() == n
+
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(option, v.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("invalid oneof option %q: %w", option, err)
+			}
+			match = v.Float() == f
+
+		default:
+			return fmt.Errorf("oneof is not supported for %s fields", v.Kind())
+		}
+
+		if match {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v is not one of [%s]", v.Interface(), strings.Join(allowed, " "))
+}
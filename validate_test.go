@@ -0,0 +1,598 @@
+package former
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+var errPasswordMismatch = errors.New("passwords do not match")
+
+type signupForm struct {
+	Password string `formfield:"password"`
+	Confirm  string `formfield:"confirm"`
+
+	validated bool
+}
+
+func (f *signupForm) Validate() error {
+	f.validated = true
+	if f.Password != f.Confirm {
+		return errPasswordMismatch
+	}
+	return nil
+}
+
+func TestPopulate_Validator(t *testing.T) {
+	t.Run("validation passes", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("password=secret&confirm=secret"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var form signupForm
+		if err := Populate(req, &form); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !form.validated {
+			t.Error("expected Validate to be called")
+		}
+	})
+
+	t.Run("validation fails", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("password=secret&confirm=typo"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var form signupForm
+		err := Populate(req, &form)
+		if !errors.Is(err, errPasswordMismatch) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		if form.Password != "secret" || form.Confirm != "typo" {
+			t.Errorf("expected fields to be bound before validation, got %+v", form)
+		}
+	})
+
+	t.Run("not called when binding fails", func(t *testing.T) {
+		var form struct {
+			signupForm
+			Age int `formfield:"age"`
+		}
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader("age=abc"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		if err := Populate(req, &form); err == nil {
+			t.Fatal("expected binding error")
+		}
+		if form.validated {
+			t.Error("expected Validate not to be called after a binding error")
+		}
+	})
+}
+
+func TestPopulate_OneOf(t *testing.T) {
+	type Form struct {
+		Role     string   `formfield:"role" oneof:"admin editor viewer"`
+		Level    int      `formfield:"level" oneof:"1 2 3"`
+		Ratio    float64  `formfield:"ratio" oneof:"0.5 1.5"`
+		Optional *string  `formfield:"optional" oneof:"a b"`
+		Tags     []string `formfield:"tags" oneof:"x y"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "allowed values", body: "role=editor&level=2&ratio=1.50&optional=b&tags=x&tags=y"},
+		{name: "empty string", body: "role=&optional="},
+		{name: "absent fields", body: ""},
+		{name: "disallowed string", body: "role=root", wantErr: "failed to set field Role: root is not one of [admin editor viewer]"},
+		{name: "disallowed int", body: "level=4", wantErr: "failed to set field Level: 4 is not one of [1 2 3]"},
+		{name: "disallowed float", body: "ratio=2", wantErr: "failed to set field Ratio: 2 is not one of [0.5 1.5]"},
+		{name: "disallowed pointer", body: "optional=c", wantErr: "failed to set field Optional: c is not one of [a b]"},
+		{name: "disallowed element", body: "tags=x&tags=z", wantErr: "failed to set field Tags: tags[1]: z is not one of [x y]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) {
+				t.Errorf("expected *BindError, got %T", err)
+			}
+		})
+	}
+
+	t.Run("invalid numeric option", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("level=1"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result struct {
+			Level int `formfield:"level" oneof:"one two"`
+		}
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), `invalid oneof option "one"`) {
+			t.Errorf("expected invalid option error, got %v", err)
+		}
+	})
+}
+
+func TestPopulate_MinMax(t *testing.T) {
+	type Form struct {
+		Qty     int     `formfield:"qty" min:"1" max:"100"`
+		Floor   int     `formfield:"floor" min:"-2"`
+		Age     uint8   `formfield:"age" max:"120"`
+		Price   float64 `formfield:"price" min:"0.01" max:"9.99"`
+		Ratings []int   `formfield:"ratings" min:"1" max:"5"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "in range", body: "qty=50&floor=-2&age=120&price=9.99&ratings=1&ratings=5"},
+		{name: "at bounds", body: "qty=1&price=0.01"},
+		{name: "int below min", body: "qty=0", wantErr: "failed to set field Qty: 0 is less than the minimum of 1"},
+		{name: "int above max", body: "qty=101", wantErr: "failed to set field Qty: 101 is greater than the maximum of 100"},
+		{name: "min only", body: "floor=-3", wantErr: "failed to set field Floor: -3 is less than the minimum of -2"},
+		{name: "max only", body: "age=121", wantErr: "failed to set field Age: 121 is greater than the maximum of 120"},
+		{name: "float below min", body: "price=0", wantErr: "failed to set field Price: 0 is less than the minimum of 0.01"},
+		{name: "float above max", body: "price=10.5", wantErr: "failed to set field Price: 10.5 is greater than the maximum of 9.99"},
+		{name: "element out of range", body: "ratings=3&ratings=6", wantErr: "failed to set field Ratings: ratings[1]: 6 is greater than the maximum of 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result struct {
+			Name string `formfield:"name" min:"1"`
+		}
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), "min is not supported for string fields") {
+			t.Errorf("expected unsupported error, got %v", err)
+		}
+	})
+}
+
+func TestPopulate_MinMaxLen(t *testing.T) {
+	type Form struct {
+		Username string  `formfield:"username" minlen:"3" maxlen:"8"`
+		Bio      string  `formfield:"bio" maxlen:"4"`
+		Nick     *string `formfield:"nick" minlen:"2"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "within bounds", body: "username=gopher&bio=hi&nick=jj"},
+		{name: "multibyte at max", body: "bio=" + url.QueryEscape("🙂🙂🙂🙂")},
+		{name: "multibyte at min", body: "username=" + url.QueryEscape("héé")},
+		{name: "multibyte over max", body: "bio=" + url.QueryEscape("🙂🙂🙂🙂🙂"), wantErr: "failed to set field Bio: length 5 is longer than the maximum of 4"},
+		{name: "too short", body: "username=go", wantErr: "failed to set field Username: length 2 is shorter than the minimum of 3"},
+		{name: "too long", body: "username=gophergopher", wantErr: "failed to set field Username: length 12 is longer than the maximum of 8"},
+		{name: "pointer too short", body: "nick=j", wantErr: "failed to set field Nick: length 1 is shorter than the minimum of 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPopulate_Pattern(t *testing.T) {
+	type Form struct {
+		Slug  string   `formfield:"slug" pattern:"^[a-z0-9-]+$"`
+		Codes []string `formfield:"codes" pattern:"^[A-Z]{3}$"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "matching values", body: "slug=hello-world-2&codes=ABC&codes=XYZ"},
+		{name: "non-matching value", body: "slug=Hello World", wantErr: `failed to set field Slug: "Hello World" does not match pattern ^[a-z0-9-]+$`},
+		{name: "non-matching element", body: "codes=ABC&codes=abcd", wantErr: `failed to set field Codes: codes[1]: "abcd" does not match pattern ^[A-Z]{3}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("malformed pattern", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("slug=abc"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result struct {
+			Slug string `formfield:"slug" pattern:"^[a-z+$"`
+		}
+		err := Populate(req, &result)
+		if err == nil || !strings.Contains(err.Error(), `invalid pattern "^[a-z+$"`) {
+			t.Errorf("expected invalid pattern error, got %v", err)
+		}
+	})
+
+	t.Run("compiled patterns are cached", func(t *testing.T) {
+		first, err := compilePattern("^cached$")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		second, _ := compilePattern("^cached$")
+		if first != second {
+			t.Error("expected the same compiled pattern to be reused")
+		}
+	})
+}
+
+func TestPopulate_RequiredIf(t *testing.T) {
+	type Company struct {
+		Name  string `formfield:"name" requiredif:"kind=business"`
+		VATID string `formfield:"vat_id" requiredif:"kind=business" msg:"VAT ID is required for businesses"`
+		Kind  string `formfield:"kind"`
+	}
+	type Form struct {
+		Type     string  `formfield:"type"`
+		Company  string  `formfield:"company" requiredif:"type=business"`
+		Shipping bool    `formfield:"shipping"`
+		Address  *string `formfield:"address" requiredif:"shipping=true"`
+		Billing  Company `formfield:"billing"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+		wantKey string
+	}{
+		{name: "condition not met", body: "type=personal"},
+		{name: "condition met and field set", body: "type=business&company=Acme"},
+		{name: "condition met and field missing", body: "type=business", wantErr: `failed to set field Company: required when type is "business"`, wantKey: "company"},
+		{name: "condition met and field empty", body: "type=business&company=", wantErr: `failed to set field Company: required when type is "business"`, wantKey: "company"},
+		{name: "bool condition", body: "shipping=on", wantErr: `failed to set field Address: required when shipping is "true"`, wantKey: "address"},
+		{name: "bool condition satisfied", body: "shipping=on&address=Main+St"},
+		{name: "nested struct", body: "billing.kind=business&billing.vat_id=X1", wantErr: `failed to set field Name: required when kind is "business"`, wantKey: "billing.name"},
+		{name: "nested struct with msg", body: "billing.kind=business&billing.name=Acme", wantErr: "VAT ID is required for businesses", wantKey: "billing.vat_id"},
+		{name: "nested struct satisfied", body: "billing.kind=business&billing.name=Acme&billing.vat_id=X1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var result Form
+			err := Populate(req, &result)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) || bindErr.Key != tt.wantKey {
+				t.Errorf("got %#v, want a BindError for key %q", err, tt.wantKey)
+			}
+		})
+	}
+
+	t.Run("invalid tags", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			target any
+			want   string
+		}{
+			{
+				name: "missing value",
+				target: &struct {
+					A string `formfield:"a" requiredif:"b"`
+					B string `formfield:"b"`
+				}{},
+				want: `invalid requiredif tag on field A: expected key=value, got "b"`,
+			},
+			{
+				name: "unknown sibling",
+				target: &struct {
+					A string `formfield:"a" requiredif:"c=1"`
+				}{},
+				want: `invalid requiredif tag on field A: no field with key "c"`,
+			},
+			{
+				name: "sibling without a single value",
+				target: &struct {
+					A string   `formfield:"a" requiredif:"b=x"`
+					B []string `formfield:"b"`
+				}{},
+				want: "invalid requiredif tag on field A: field B cannot be compared: unsupported field type: slice",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := Populate(httptest.NewRequest("GET", "/", nil), tt.target)
+				if err == nil || err.Error() != tt.want {
+					t.Errorf("error = %v, want %q", err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+type orderForm struct {
+	Email    string   `formfield:"email" required:"true" pattern:"^[^@]+@[^@]+$"`
+	Qty      int      `formfield:"qty" min:"1" max:"10"`
+	Size     string   `formfield:"size" oneof:"S M L"`
+	Coupon   string   `formfield:"coupon" maxlen:"8" msg:"Coupon codes are at most 8 characters"`
+	Delivery string   `formfield:"delivery"`
+	Address  string   `formfield:"address" requiredif:"delivery=ship"`
+	Notes    []string `formfield:"notes" minlen:"2"`
+	Contact  Contact  `formfield:"contact"`
+
+	validated bool
+}
+
+func (f *orderForm) Validate() error {
+	f.validated = true
+	return nil
+}
+
+func TestBindAndValidate(t *testing.T) {
+	t.Run("all failures reported", func(t *testing.T) {
+		values := url.Values{
+			"email":         {"not-an-email"},
+			"qty":           {"0"},
+			"size":          {"XL"},
+			"coupon":        {"TOOLONGCODE"},
+			"delivery":      {"ship"},
+			"notes":         {"ok", "x"},
+			"contact.phone": {"555"},
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var form orderForm
+		err := BindAndValidate(req, &form)
+
+		var errs Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected Errors, got %T: %v", err, err)
+		}
+
+		wantKeys := []string{"email", "qty", "size", "coupon", "notes", "address"}
+		var gotKeys []string
+		for _, e := range errs {
+			gotKeys = append(gotKeys, e.Key)
+		}
+		if strings.Join(gotKeys, ",") != strings.Join(wantKeys, ",") {
+			t.Errorf("failed keys = %v, want %v", gotKeys, wantKeys)
+		}
+
+		if !strings.Contains(err.Error(), "Coupon codes are at most 8 characters") ||
+			!strings.Contains(err.Error(), `failed to set field Address: required when delivery is "ship"`) {
+			t.Errorf("error = %q, want every failure listed", err)
+		}
+
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "Email" {
+			t.Errorf("errors.As found %v, want the first BindError", bindErr)
+		}
+
+		if form.Contact.Phone != "555" {
+			t.Errorf("valid fields should still be bound, got %+v", form.Contact)
+		}
+		if form.validated {
+			t.Error("Validate should not run when fields failed")
+		}
+	})
+
+	t.Run("conversion failures are collected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?email=a@b.c&qty=many&size=XXL", nil)
+
+		var form orderForm
+		err := BindAndValidate(req, &form)
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Key != "qty" || errs[1].Key != "size" {
+			t.Errorf("got %v, want failures for qty and size", err)
+		}
+	})
+
+	t.Run("invalid JSON for a nested struct is collected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?email=a@b.c&qty=many&contact={bad}", nil)
+
+		var form orderForm
+		err := BindAndValidate(req, &form)
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Key != "qty" || errs[1].Key != "contact" || errs[1].Field != "Contact" {
+			t.Errorf("got %v, want failures for qty and contact", err)
+		}
+	})
+
+	t.Run("required field missing", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?qty=2", nil)
+
+		var form orderForm
+		err := BindAndValidate(req, &form)
+		if err == nil || err.Error() != "failed to set field Email: a value is required" {
+			t.Errorf("error = %v, want the missing email reported", err)
+		}
+	})
+
+	t.Run("valid form", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?email=a@b.c&qty=2&size=M&delivery=ship&address=Main+St", nil)
+
+		var form orderForm
+		if err := BindAndValidate(req, &form); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !form.validated {
+			t.Error("expected Validate to run")
+		}
+	})
+
+	t.Run("Populate stops at the first failure", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?email=bad&qty=0", nil)
+
+		var form orderForm
+		err := Populate(req, &form)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Key != "email" {
+			t.Errorf("got %v, want a single BindError for email", err)
+		}
+		var errs Errors
+		if errors.As(err, &errs) {
+			t.Error("Populate should not return Errors")
+		}
+	})
+
+	t.Run("non-field errors are returned as is", func(t *testing.T) {
+		err := BindAndValidate(httptest.NewRequest("GET", "/", nil), orderForm{})
+		if err == nil || err.Error() != "dest must be a pointer to a struct" {
+			t.Errorf("error = %v, want the dest error", err)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("failure leaves dest untouched", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?email=new@example.com&qty=0&notes=a&notes=b", nil)
+
+		form := orderForm{Email: "old@example.com", Qty: 3, Notes: []string{"keep"}}
+		err := Validate(req, &form)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Key != "qty" {
+			t.Fatalf("got %v, want a BindError for qty", err)
+		}
+		if form.Email != "old@example.com" || form.Qty != 3 || len(form.Notes) != 1 || form.Notes[0] != "keep" || form.validated {
+			t.Errorf("dest was changed: %+v", form)
+		}
+	})
+
+	t.Run("success leaves dest untouched", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?email=a@b.c&qty=2&size=M", nil)
+
+		var form orderForm
+		if err := Validate(req, &form); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if form.Email != "" || form.Qty != 0 || form.validated {
+			t.Errorf("dest was changed: %+v", form)
+		}
+	})
+
+	t.Run("existing values count", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?qty=2", nil)
+
+		form := orderForm{Email: "old@example.com"}
+		if err := Populate(req, &orderForm{Email: "old@example.com"}); err != nil {
+			t.Fatalf("Populate: unexpected error: %v", err)
+		}
+		if err := Validate(req, &form); err != nil {
+			t.Errorf("Validate: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("pointer targets are not written through", func(t *testing.T) {
+		type Form struct {
+			Age     *int     `formfield:"age"`
+			Contact *Contact `formfield:"contact"`
+		}
+		req := httptest.NewRequest("GET", "/?age=40&contact.email=new@example.com", nil)
+
+		age := 30
+		form := Form{Age: &age, Contact: &Contact{Email: "old@example.com"}}
+		if err := Validate(req, &form); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if age != 30 || form.Contact.Email != "old@example.com" {
+			t.Errorf("dest was changed: age %d, contact %+v", age, *form.Contact)
+		}
+	})
+
+	t.Run("Validate method runs on the copy", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?password=a&confirm=b", nil)
+
+		var form signupForm
+		if err := Validate(req, &form); !errors.Is(err, errPasswordMismatch) {
+			t.Errorf("error = %v, want %v", err, errPasswordMismatch)
+		}
+		if form.validated {
+			t.Error("Validate ran on dest instead of a copy")
+		}
+	})
+
+	t.Run("invalid dest", func(t *testing.T) {
+		err := Validate(httptest.NewRequest("GET", "/", nil), orderForm{})
+		if err == nil || err.Error() != "dest must be a pointer to a struct" {
+			t.Errorf("error = %v, want the dest error", err)
+		}
+	})
+}